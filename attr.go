@@ -0,0 +1,133 @@
+package log
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Attr is an alias for [slog.Attr].
+type Attr = slog.Attr
+
+// String returns an Attr for a string value.
+func String(key, value string) Attr {
+	return slog.String(key, value)
+}
+
+// Int returns an Attr for an int value.
+func Int(key string, value int) Attr {
+	return slog.Int(key, value)
+}
+
+// Int64 returns an Attr for an int64 value.
+func Int64(key string, value int64) Attr {
+	return slog.Int64(key, value)
+}
+
+// Uint64 returns an Attr for a uint64 value.
+func Uint64(key string, value uint64) Attr {
+	return slog.Uint64(key, value)
+}
+
+// Float64 returns an Attr for a float64 value.
+func Float64(key string, value float64) Attr {
+	return slog.Float64(key, value)
+}
+
+// Bool returns an Attr for a bool value.
+func Bool(key string, value bool) Attr {
+	return slog.Bool(key, value)
+}
+
+// Time returns an Attr for a [time.Time] value.
+func Time(key string, value time.Time) Attr {
+	return slog.Time(key, value)
+}
+
+// Duration returns an Attr for a [time.Duration] value.
+func Duration(key string, value time.Duration) Attr {
+	return slog.Duration(key, value)
+}
+
+// Any returns an Attr for any value, see [slog.Any].
+func Any(key string, value any) Attr {
+	return slog.Any(key, value)
+}
+
+// Group returns an Attr for a group of attributes, see [slog.Group].
+func Group(key string, args ...any) Attr {
+	return slog.Group(key, args...)
+}
+
+// LazyValue is an attribute value computed only when a record is written.
+type LazyValue func() any
+
+var _ slog.LogValuer = LazyValue(nil)
+
+// Lazy returns a value that calls f when a record passes the level check,
+// and not when the level is disabled. Use it for attribute values that are
+// expensive to compute:
+//
+//	logger.Debug("state", "dump", log.Lazy(func() any { return dump(s) }))
+//
+// The function is called once per record, when the logger handles it, and
+// its result is used by the rules, hooks, [Ring], flight recorder and
+// formatters alike.
+func Lazy(f func() any) LazyValue {
+	return LazyValue(f)
+}
+
+// LogValue calls the function and returns its result as a [slog.Value].
+func (f LazyValue) LogValue() slog.Value {
+	return slog.AnyValue(f())
+}
+
+// resolveRecord returns a copy of the record with its attribute values
+// resolved, including the values in groups, so that lazy values are
+// computed once. It returns the record itself if no value needs resolving.
+func resolveRecord(r slog.Record) slog.Record {
+	resolved := true
+	r.Attrs(func(a slog.Attr) bool {
+		resolved = isResolved(a.Value)
+		return resolved
+	})
+	if resolved {
+		return r
+	}
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		nr.AddAttrs(resolveAttr(a))
+		return true
+	})
+	return nr
+}
+
+// resolveAttr resolves the value of the attribute, including the values in
+// groups.
+func resolveAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		group := a.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			attrs[i] = resolveAttr(ga)
+		}
+		a.Value = slog.GroupValue(attrs...)
+	}
+	return a
+}
+
+// isResolved reports whether the value, and the values in it if it is a
+// group, need no resolving.
+func isResolved(v slog.Value) bool {
+	switch v.Kind() {
+	case slog.KindLogValuer:
+		return false
+	case slog.KindGroup:
+		for _, a := range v.Group() {
+			if !isResolved(a.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
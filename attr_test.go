@@ -0,0 +1,52 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	for _, f := range []log.Formatter{log.TextFormatter, log.JSONFormatter, log.LogfmtFormatter} {
+		var (
+			buf   bytes.Buffer
+			calls int
+		)
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(f))
+		lazy := log.Lazy(func() any {
+			calls++
+			return "expensive"
+		})
+
+		logger.Debug("test message", "state", lazy)
+		assert.Zero(t, calls)
+		assert.Empty(t, buf.String())
+
+		logger.Info("test message", "state", lazy)
+		assert.Equal(t, 1, calls)
+		assert.Contains(t, buf.String(), "expensive")
+	}
+
+	t.Run("Once", func(t *testing.T) {
+		var buf bytes.Buffer
+		calls := 0
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseRing(log.NewRing(10, log.DebugLevel)),
+			log.UseRules(log.Rule{When: []log.Condition{{Field: "state", Op: "=", Value: "other"}}, Drop: true}),
+			log.UseHooks(log.NewHook(func(context.Context, slog.Record) error { return nil })),
+			log.UseDedup(time.Minute),
+		)
+		logger.Info("test message", "state", log.Lazy(func() any {
+			calls++
+			return "expensive"
+		}))
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, "INFO test message state=expensive\n", buf.String())
+	})
+}
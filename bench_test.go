@@ -0,0 +1,124 @@
+package log_test
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// outputLeveler is implemented by the handler of the default logger.
+type outputLeveler interface {
+	GetOutput() io.Writer
+	GetLevel() log.Level
+}
+
+// useDefault configures the default logger for benchmarking and restores it
+// when the test ends.
+func useDefault(tb testing.TB) {
+	tb.Helper()
+	h := log.Default().Handler().(outputLeveler)
+	w, level := h.GetOutput(), h.GetLevel()
+	log.SetOutput(&discard{})
+	log.SetLevel(log.InfoLevel)
+	tb.Cleanup(func() {
+		log.SetOutput(w)
+		log.SetLevel(level)
+		log.SetFormatter(log.TextFormatter)
+	})
+}
+
+func TestDisabledAllocs(t *testing.T) {
+	useDefault(t)
+	err := errors.New("boom")
+	tests := map[string]func(){
+		"Debug":     func() { log.Debug("message") },
+		"DebugArgs": func() { log.Debug("message", "key", "value", "n", 42) },
+		"Debugf":    func() { log.Debugf("message %s", "value") },
+		"LogAttrs": func() {
+			log.LogAttrs(log.DebugLevel, "message",
+				log.String("key", "value"),
+				log.Int("n", 4242),
+				log.Duration("elapsed", time.Second),
+				log.Any("err", err),
+			)
+		},
+	}
+	for name, f := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Zero(t, testing.AllocsPerRun(100, f))
+		})
+	}
+}
+
+func TestEnabledAllocs(t *testing.T) {
+	useDefault(t)
+	for _, tt := range []struct {
+		name      string
+		formatter log.Formatter
+		max       float64 // max is an upper bound, to catch regressions.
+	}{
+		{"Text", log.TextFormatter, 40},
+		{"JSON", log.JSONFormatter, 4},
+		{"Logfmt", log.LogfmtFormatter, 20},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			log.SetFormatter(tt.formatter)
+			allocs := testing.AllocsPerRun(100, func() {
+				log.LogAttrs(log.InfoLevel, "message",
+					log.String("key", "value"),
+					log.Int("n", 4242),
+					log.Duration("elapsed", time.Second),
+				)
+			})
+			assert.LessOrEqual(t, allocs, tt.max)
+		})
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	useDefault(b)
+	b.Run("Debug", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			log.Debug("message", "key", "value")
+		}
+	})
+	b.Run("LogAttrs", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := range b.N {
+			log.LogAttrs(log.DebugLevel, "message",
+				log.String("key", "value"),
+				log.Int("n", i),
+				log.Duration("elapsed", time.Second),
+			)
+		}
+	})
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	for _, tt := range []struct {
+		name      string
+		formatter log.Formatter
+	}{
+		{"Text", log.TextFormatter},
+		{"JSON", log.JSONFormatter},
+		{"Logfmt", log.LogfmtFormatter},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			useDefault(b)
+			log.SetFormatter(tt.formatter)
+			b.ReportAllocs()
+			for i := range b.N {
+				log.LogAttrs(log.InfoLevel, "message",
+					log.String("key", "value"),
+					log.Int("n", i),
+					log.Duration("elapsed", time.Second),
+				)
+			}
+		})
+	}
+}
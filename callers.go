@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FunctionCallerFormatter formats the caller as the function name, without
+// the package, such as "(*Server).Serve".
+func FunctionCallerFormatter(_ string, _ int, fn string) string {
+	_, name := splitFuncName(fn)
+	return name
+}
+
+// PackageCallerFormatter formats the caller as the import path of the
+// package, such as "github.com/user/app/internal/db".
+func PackageCallerFormatter(_ string, _ int, fn string) string {
+	pkg, _ := splitFuncName(fn)
+	return pkg
+}
+
+// CompactCallerFormatter formats the caller as the package name, function
+// name and line, such as "db.Open:42".
+func CompactCallerFormatter(_ string, line int, fn string) string {
+	pkg, name := splitFuncName(fn)
+	return path.Base(pkg) + "." + name + ":" + strconv.Itoa(line)
+}
+
+// ModuleCallerFormatter formats the caller as the path relative to the root
+// of the main module and the line, such as "internal/db/db.go:42". The main
+// module is read from the build info. Callers in other modules are
+// formatted with the import path of their package, such as
+// "github.com/user/lib/lib.go:42".
+func ModuleCallerFormatter(file string, line int, fn string) string {
+	pkg, _ := splitFuncName(fn)
+	mainPath, mainPkg := mainModule()
+	if pkg == "main" && mainPkg != "" {
+		pkg = mainPkg
+	}
+	dir := pkg
+	if rel, ok := strings.CutPrefix(pkg, mainPath); ok && mainPath != "" && (rel == "" || rel[0] == '/') {
+		dir = strings.TrimPrefix(rel, "/")
+	}
+	return path.Join(dir, filepath.Base(file)) + ":" + strconv.Itoa(line)
+}
+
+// mainModule returns the path of the main module and the import path of
+// the main package, from the build info.
+var mainModule = sync.OnceValues(func() (string, string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	return bi.Main.Path, bi.Path
+})
+
+// splitFuncName splits a fully qualified function name, as reported by the
+// runtime, into the import path of the package and the function name.
+func splitFuncName(fn string) (pkg, name string) {
+	slash := strings.LastIndexByte(fn, '/')
+	dot := strings.IndexByte(fn[slash+1:], '.')
+	if dot < 0 {
+		return fn, ""
+	}
+	dot += slash + 1
+	// Dots in the last element of the import path are escaped.
+	return strings.ReplaceAll(fn[:dot], "%2e", "."), fn[dot+1:]
+}
+
+// callerFields is the value of the caller field of the JSON formatter when
+// it is written as an object, see [Options.CallerFields].
+type callerFields struct {
+	file     string
+	line     int
+	function string
+}
+
+// writeJSON writes the caller as a JSON object.
+func (c callerFields) writeJSON(b *bytes.Buffer) {
+	b.WriteString(`{"file":`)
+	writeJSONString(b, c.file)
+	b.WriteString(`,"line":`)
+	b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(c.line), 10))
+	b.WriteString(`,"function":`)
+	writeJSONString(b, c.function)
+	b.WriteByte('}')
+}
@@ -0,0 +1,67 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallerFormatters(t *testing.T) {
+	const (
+		file = "/home/user/app/internal/db/db.go"
+		fn   = "github.com/bartventer/log/internal/db.(*Conn).Open"
+	)
+	tests := []struct {
+		name string
+		f    log.CallerFormatter
+		fn   string
+		want string
+	}{
+		{"Function", log.FunctionCallerFormatter, fn, "(*Conn).Open"},
+		{"Package", log.PackageCallerFormatter, fn, "github.com/bartventer/log/internal/db"},
+		{"PackageEscaped", log.PackageCallerFormatter, "gopkg.in/yaml%2ev3.Marshal", "gopkg.in/yaml.v3"},
+		{"Compact", log.CompactCallerFormatter, fn, "db.(*Conn).Open:42"},
+		{"ModuleMain", log.ModuleCallerFormatter, fn, "internal/db/db.go:42"},
+		{"ModuleOther", log.ModuleCallerFormatter, "github.com/user/lib/db.Open", "github.com/user/lib/db/db.go:42"},
+		{"ModulePrefix", log.ModuleCallerFormatter, "github.com/bartventer/logger.Open", "github.com/bartventer/logger/db.go:42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.f(file, 42, tt.fn))
+		})
+	}
+
+	t.Run("Logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), log.UseCallerFormatter(log.CompactCallerFormatter))
+		_, _, line, _ := runtime.Caller(0)
+		logger.Info("message")
+		assert.Equal(t, "INFO <log_test.TestCallerFormatters.func2:"+strconv.Itoa(line+1)+"> message\n", buf.String())
+	})
+}
+
+func TestCallerFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.JSONFormatter),
+		log.UseReportCaller(true), log.UseCallerFields(true))
+	_, file, line, _ := runtime.Caller(0)
+	logger.Info("message")
+
+	var got struct {
+		Caller struct {
+			File     string `json:"file"`
+			Line     int    `json:"line"`
+			Function string `json:"function"`
+		} `json:"caller"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, file, got.Caller.File)
+	assert.Equal(t, line+1, got.Caller.Line)
+	assert.Equal(t, "github.com/bartventer/log_test.TestCallerFields", got.Caller.Function)
+}
@@ -0,0 +1,162 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Columns configure the columnar layout of the text formatter. The
+// timestamp, level, caller and prefix occupy fixed-width columns, and the
+// attributes start at a consistent column after the message.
+//
+// A column with a positive width is padded, or truncated, to that width,
+// and is left blank for records without the field, so that the following
+// columns stay aligned. A column with a zero width is written as is, so the
+// zero value of Columns aligns nothing; start from [DefaultColumns] for the
+// widths noted below.
+type Columns struct {
+	Timestamp int // Timestamp is the width of the timestamp column. It is 0 in [DefaultColumns].
+	Level     int // Level is the width of the level column. It is 5 in [DefaultColumns].
+	Caller    int // Caller is the width of the caller column. It is 24 in [DefaultColumns].
+	Prefix    int // Prefix is the width of the prefix column. It is 0 in [DefaultColumns].
+
+	// Message is the width to which the message is padded when attributes
+	// follow it. Longer messages are not truncated. It is 40 in
+	// [DefaultColumns].
+	Message int
+
+	// Width is the line width at which attributes wrap, aligned under the
+	// message. If it is 0, it is the width of the terminal when writing to
+	// one, and no wrapping otherwise. The terminal width is read when the
+	// output is set, see [SetOutput], so later resizes of the terminal are
+	// not taken into account.
+	Width int
+}
+
+// DefaultColumns returns the default columns.
+func DefaultColumns() Columns {
+	return Columns{
+		Level:   5,
+		Caller:  24,
+		Message: 40,
+	}
+}
+
+// columnFormatter writes the entry as styled text in columns. It must be
+// called with the lock held.
+func (h *handler) columnFormatter(b *bytes.Buffer, e entry) {
+	c := h.columns
+	st := h.styles
+	first := true
+	start := b.Len()
+	cell := func(s string, width int) {
+		writeSpace(b, first)
+		first = false
+		b.WriteString(fitColumn(s, width))
+	}
+
+	if e.time.Key != "" {
+		cell(st.Timestamp.Renderer(h.re).Render(h.clean(h.formatTime(e.time.Value))), c.Timestamp)
+	}
+	if e.lvl.Key != "" || c.Level > 0 {
+		var lvl string
+		if e.lvl.Key != "" {
+			lvl = h.formatLevel(e.lvl.Value)
+		}
+		cell(lvl, c.Level)
+	}
+	if e.caller.Key != "" || (h.reportCaller && c.Caller > 0) {
+		var caller string
+		if e.caller.Key != "" {
+			caller = st.Caller.Renderer(h.re).Render("<" + h.clean(e.caller.Value.String()) + ">")
+		}
+		if c.Caller > 0 {
+			// Truncate before linking, so that the link is kept intact.
+			caller = ansi.Truncate(caller, c.Caller, "…")
+		}
+		cell(h.linkCaller(caller, e), c.Caller)
+	}
+	if e.prefix.Key != "" || c.Prefix > 0 {
+		var prefix string
+		if e.prefix.Key != "" {
+			prefix = st.Prefix.Renderer(h.re).Render(h.clean(e.prefix.Value.String()) + ":")
+		}
+		cell(prefix, c.Prefix)
+	}
+
+	hasAttrs := false
+	for _, a := range e.attrs {
+		if a.Key != "" {
+			hasAttrs = true
+			break
+		}
+	}
+	if e.message.Key != "" || hasAttrs {
+		writeSpace(b, first)
+		first = false
+	}
+	// The attributes start at the column after the padded message, and
+	// continuation lines are aligned under the message.
+	msgColumn := lineWidth(b)
+	attrColumn := msgColumn + c.Message + 1
+	if e.message.Key != "" {
+		b.WriteString(st.Message.Renderer(h.re).Render(h.clean(e.message.Value.String())))
+	}
+
+	width := c.Width
+	if width == 0 {
+		width = h.termWidth
+	}
+	write := func(s string) {
+		col := lineWidth(b)
+		header := bytes.IndexByte(b.Bytes()[start:], '\n') < 0
+		switch {
+		case header && col < attrColumn:
+			b.WriteString(strings.Repeat(" ", attrColumn-col))
+		case col == 0:
+			// After a multi-line value.
+			b.WriteString(strings.Repeat(" ", msgColumn))
+		case width > 0 && col > msgColumn && col+1+lipgloss.Width(s) > width:
+			b.WriteByte('\n')
+			b.WriteString(strings.Repeat(" ", msgColumn))
+		default:
+			b.WriteByte(' ')
+		}
+		b.WriteString(s)
+	}
+	// Multi-line values are indented to the column of the wrapped
+	// attributes.
+	h.writeTextAttrs(b, e.attrs, write, &first, strings.Repeat(" ", max(msgColumn-len("  "), 0)))
+
+	// Remove the padding of blank trailing columns.
+	if !hasAttrs {
+		b.Truncate(len(bytes.TrimRight(b.Bytes(), " ")))
+	}
+	b.WriteByte('\n')
+}
+
+// fitColumn pads s with spaces to the width, or truncates it. A zero
+// width leaves s unchanged.
+func fitColumn(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	w := lipgloss.Width(s)
+	switch {
+	case w > width:
+		return ansi.Truncate(s, width, "…")
+	case w < width:
+		return s + strings.Repeat(" ", width-w)
+	default:
+		return s
+	}
+}
+
+// lineWidth returns the display width of the last line of b.
+func lineWidth(b *bytes.Buffer) int {
+	p := b.Bytes()
+	return lipgloss.Width(string(p[bytes.LastIndexByte(p, '\n')+1:]))
+}
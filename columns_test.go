@@ -0,0 +1,56 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumns(t *testing.T) {
+	t.Run("Aligned", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseColumns(log.Columns{Level: 5, Message: 8}))
+		logger.Info("start", "a", 1)
+		logger.Warn("stopped", "b", 2, "c", 3)
+		logger.Error("no attrs")
+		assert.Equal(t, ""+
+			"INFO  start    a=1\n"+
+			"WARN  stopped  b=2 c=3\n"+
+			"ERROR no attrs\n", buf.String())
+	})
+
+	t.Run("Prefix", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseColumns(log.Columns{Level: 5, Prefix: 6}))
+		logger.Info("one")
+		log.WithPrefix(logger, "db").Info("two")
+		log.WithPrefix(logger, "network").Info("three")
+		assert.Equal(t, ""+
+			"INFO         one\n"+
+			"INFO  db:    two\n"+
+			"INFO  netwo… three\n", buf.String())
+	})
+
+	t.Run("Wrap", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseColumns(log.Columns{Level: 4, Message: 5, Width: 20}))
+		logger.Info("msg", "a", 1, "bb", 22, "ccc", 333)
+		assert.Equal(t, ""+
+			"INFO msg   a=1 bb=22\n"+
+			"     ccc=333\n", buf.String())
+	})
+
+	t.Run("MultiLine", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseColumns(log.Columns{Level: 4, Message: 5}), log.UseSanitize(false))
+		logger.Info("msg", "text", "one\ntwo", "a", 1)
+		assert.Equal(t, ""+
+			"INFO msg\n"+
+			"     text=\n"+
+			"     │ one\n"+
+			"     │ two\n"+
+			"     a=1\n", buf.String())
+	})
+}
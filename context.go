@@ -1,6 +1,13 @@
 package log
 
-import "context"
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
 
 type contextKey struct{ string }
 
@@ -21,3 +28,92 @@ func FromContext(ctx context.Context) *Logger {
 	}
 	return Default()
 }
+
+// maxScopeRecords is the maximum number of records held in a scope. The
+// oldest records are discarded first.
+const maxScopeRecords = 1000
+
+type scopeKey struct{}
+
+// scope holds the records of a flight recorder scope.
+type scope struct {
+	mu    sync.Mutex
+	recs  []scopedRecord
+	ended atomic.Bool
+}
+
+// scopedRecord is a held record, the handler that will write it and the
+// writer it is routed to, if any.
+type scopedRecord struct {
+	h *handler
+	w io.Writer
+	r slog.Record
+}
+
+// WithScope returns a copy of ctx with a new flight recorder scope, and a
+// function that ends the scope, discarding the records it holds. Loggers
+// using [UseFlightRecorder] hold the records below their level that are
+// logged with the returned context until a record with level Error or
+// higher is logged with it.
+//
+// The held records are then written before that record, sorted by time.
+// Since the records at or above the level are written when they are
+// logged, the held records follow the records written in the meantime;
+// their timestamps are the time they were logged.
+func WithScope(ctx context.Context) (context.Context, func()) {
+	s := &scope{}
+	return context.WithValue(ctx, scopeKey{}, s), s.end
+}
+
+// scopeFromContext returns the scope of ctx, or nil.
+func scopeFromContext(ctx context.Context) *scope {
+	if ctx == nil {
+		return nil
+	}
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	return s
+}
+
+// add holds a copy of the record.
+func (s *scope) add(h *handler, w io.Writer, r slog.Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended.Load() {
+		return
+	}
+	if len(s.recs) == maxScopeRecords {
+		s.recs[0] = scopedRecord{}
+		s.recs = s.recs[1:]
+	}
+	s.recs = append(s.recs, scopedRecord{h: h, w: w, r: r.Clone()})
+}
+
+// drain removes and returns the held records, sorted by time.
+func (s *scope) drain() []scopedRecord {
+	s.mu.Lock()
+	recs := s.recs
+	s.recs = nil
+	s.mu.Unlock()
+	slices.SortStableFunc(recs, func(a, b scopedRecord) int {
+		return a.r.Time.Compare(b.r.Time)
+	})
+	return recs
+}
+
+// end discards the held records and stops holding new ones.
+func (s *scope) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ended.Store(true)
+	s.recs = nil
+}
+
+// recording reports whether records at the given level are held in the
+// scope of ctx. Records are not held once the scope has ended.
+func (h *handler) recording(ctx context.Context, level Level) bool {
+	if h.flight == nil || level < *h.flight || atomic.LoadUint32(&h.isDiscard) != 0 {
+		return false
+	}
+	s := scopeFromContext(ctx)
+	return s != nil && !s.ended.Load()
+}
@@ -1,8 +1,11 @@
 package log
 
 import (
+	"bytes"
 	"context"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -23,3 +26,50 @@ func TestFromContext(t *testing.T) {
 	assert.Equal(t, logger, loggerFromCtx)
 
 }
+
+func TestWithScope(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(UseOutput(&buf), UseFlightRecorder(DebugLevel))
+
+	ctx, end := WithScope(context.Background())
+	assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+	assert.False(t, logger.Enabled(ctx, slog.LevelDebug-4))
+	assert.False(t, logger.Enabled(context.Background(), slog.LevelDebug))
+
+	logger.DebugContext(ctx, "one")
+	logger.InfoContext(ctx, "two")
+	logger.Debug("no scope")
+	assert.Equal(t, "INFO two\n", buf.String())
+
+	// The held records are written before the error, after the records
+	// written in the meantime.
+	logger.ErrorContext(ctx, "three")
+	assert.Equal(t, "INFO two\nDEBUG one\nERROR three\n", buf.String())
+
+	buf.Reset()
+	logger.DebugContext(ctx, "four")
+	end()
+	assert.False(t, logger.Enabled(ctx, slog.LevelDebug))
+	logger.DebugContext(ctx, "five")
+	logger.ErrorContext(ctx, "six")
+	assert.Equal(t, "ERROR six\n", buf.String())
+}
+
+func TestWithScopeOrder(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(UseOutput(&buf), UseFlightRecorder(DebugLevel), UseReportTimestamp(true), UseTimeFormat(time.TimeOnly))
+	ctx, end := WithScope(context.Background())
+	defer end()
+
+	// Records handled out of order, as by concurrent goroutines.
+	t0 := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	h := logger.Handler()
+	for _, r := range []slog.Record{
+		slog.NewRecord(t0.Add(2*time.Second), slog.LevelDebug, "two", 0),
+		slog.NewRecord(t0.Add(time.Second), slog.LevelDebug, "one", 0),
+		slog.NewRecord(t0.Add(3*time.Second), slog.LevelError, "three", 0),
+	} {
+		assert.NoError(t, h.Handle(ctx, r))
+	}
+	assert.Equal(t, "12:00:01 DEBUG one\n12:00:02 DEBUG two\n12:00:03 ERROR three\n", buf.String())
+}
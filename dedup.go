@@ -0,0 +1,117 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// dedup collapses consecutive identical records into a summary record.
+type dedup struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	key   string
+	h     *handler  // h is the handler that wrote the first record of the run.
+	w     io.Writer // w is the writer of the run, or nil for the output.
+	level slog.Level
+	first time.Time
+	last  time.Time
+	count int // count is the number of suppressed repeats.
+	timer *time.Timer
+}
+
+// newDedup returns a dedup collapsing identical records within window.
+func newDedup(window time.Duration) *dedup {
+	return &dedup{window: window}
+}
+
+// suppress reports whether the record repeats the current run and must not
+// be written. If the record ends a run with repeats, the summary record of
+// the run is written first.
+func (d *dedup) suppress(ctx context.Context, h *handler, w io.Writer, r slog.Record) bool {
+	key := h.dedupKey(r)
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	d.mu.Lock()
+	if key == d.key && w == d.w && t.Sub(d.first) <= d.window {
+		d.last = t
+		d.count++
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.first.Add(d.window).Sub(time.Now()), d.expire)
+		}
+		d.mu.Unlock()
+		return true
+	}
+	summary, sh, sw := d.end()
+	d.key, d.h, d.w, d.level, d.first, d.last = key, h, w, r.Level, t, t
+	d.mu.Unlock()
+
+	if sh != nil {
+		_ = sh.output(ctx, sw, summary)
+	}
+	return false
+}
+
+// expire ends the current run when its window has elapsed.
+func (d *dedup) expire() {
+	d.flush()
+}
+
+// flush ends the current run, writing its summary record if it has repeats.
+func (d *dedup) flush() {
+	d.mu.Lock()
+	summary, sh, sw := d.end()
+	d.key, d.h, d.w = "", nil, nil
+	d.mu.Unlock()
+
+	if sh != nil {
+		_ = sh.output(context.Background(), sw, summary)
+	}
+}
+
+// end ends the current run, returning its summary record, handler and
+// writer if it has repeats. It must be called with the lock held.
+func (d *dedup) end() (slog.Record, *handler, io.Writer) {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.count == 0 {
+		return slog.Record{}, nil, nil
+	}
+	r := slog.NewRecord(d.last, d.level,
+		fmt.Sprintf("last message repeated %d times", d.count), 0)
+	r.AddAttrs(
+		slog.Int("repeated", d.count),
+		slog.Time("first", d.first),
+		slog.Time("last", d.last),
+	)
+	d.count = 0
+	return r, d.h, d.w
+}
+
+// dedupKey returns the key identifying identical records.
+func (h *handler) dedupKey(r slog.Record) string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d\x00%s\x00%s", r.Level, h.prefix, r.Message)
+	attrs := append([]slog.Attr(nil), h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = h.appendAttrs(attrs, h.groups, a)
+		return true
+	})
+	for _, a := range attrs {
+		b.WriteString("\x00" + a.Key + "=" + a.Value.String())
+	}
+	return b.String()
+}
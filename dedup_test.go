@@ -0,0 +1,96 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a [bytes.Buffer] safe for concurrent use.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestDedup(t *testing.T) {
+	t.Run("RunEndsWithDifferentRecord", func(t *testing.T) {
+		var buf syncBuffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.LogfmtFormatter),
+			log.UseDedup(time.Minute),
+		)
+		for range 3 {
+			logger.Warn("retrying", "dep", "db")
+		}
+		logger.Warn("retrying", "dep", "cache")
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		assert.Len(t, lines, 3)
+		assert.Equal(t, "level=warn msg=retrying dep=db", lines[0])
+		assert.Regexp(t, `^level=warn msg="last message repeated 2 times" repeated=2 first=\S+ last=\S+$`, lines[1])
+		assert.Equal(t, "level=warn msg=retrying dep=cache", lines[2])
+	})
+
+	t.Run("RunEndsWithWindow", func(t *testing.T) {
+		var buf syncBuffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.LogfmtFormatter),
+			log.UseDedup(20*time.Millisecond),
+		)
+		logger.Info("tick")
+		logger.Info("tick")
+		assert.Eventually(t, func() bool {
+			return strings.Contains(buf.String(), "last message repeated 1 times")
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestDedupLazy(t *testing.T) {
+	var buf syncBuffer
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseFormatter(log.LogfmtFormatter),
+		log.UseDedup(time.Minute),
+	)
+	calls := 0
+	logger.Info("state", "n", log.Lazy(func() any { calls++; return 1 }))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "level=info msg=state n=1\n", buf.String())
+}
+
+func TestDedupFlush(t *testing.T) {
+	var buf syncBuffer
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseFormatter(log.LogfmtFormatter),
+		log.UseDedup(time.Minute),
+	)
+	for range 3 {
+		logger.Error("shutting down")
+	}
+	log.Flush(logger)
+	log.Flush(logger)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Regexp(t, `^level=error msg="last message repeated 2 times" repeated=2 `, lines[1])
+}
@@ -0,0 +1,473 @@
+package log
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	segmentExt     = ".seg"
+	checkpointFile = "checkpoint"
+	frameHeaderLen = 4
+)
+
+// ErrDiskBufferClosed is returned when writing to a closed [DiskBuffer].
+var ErrDiskBufferClosed = errors.New("log: disk buffer closed")
+
+// errCorruptSegment reports a frame length that no record can have.
+var errCorruptSegment = errors.New("corrupt segment")
+
+type (
+	// DiskBufferOptions can be used to configure a [DiskBuffer].
+	DiskBufferOptions struct {
+		SegmentSize   int64         // SegmentSize is the maximum size of a segment file in bytes, which bounds the size of a record. Default is 4 MiB.
+		MaxSize       int64         // MaxSize is the maximum total size of the segment files in bytes. Default is 256 MiB.
+		RetryInterval time.Duration // RetryInterval is the delay before retrying a failed forward. Default is 1s.
+		BatchSize     int           // BatchSize is the number of records forwarded to a [Flusher] between acknowledgements. Default is 100.
+		Sync          bool          // Sync is whether each write is synced to disk. Default is false.
+		Logger        *slog.Logger  // Logger receives eviction warnings. Default is the default logger.
+	}
+
+	// DiskBufferOption is a disk buffer option.
+	DiskBufferOption func(*DiskBufferOptions)
+
+	// DiskBufferStats are the counters of a [DiskBuffer].
+	DiskBufferStats struct {
+		Segments int    // Segments is the number of segment files on disk.
+		Bytes    int64  // Bytes is the total size of the segment files.
+		Evicted  uint64 // Evicted is the number of segments evicted because the buffer was full.
+	}
+)
+
+// DiskBuffer is an [io.Writer] that appends records to segment files in a
+// directory and forwards them to a downstream writer. Each call to Write is
+// treated as one record.
+//
+// Segments are deleted once all their records have been forwarded, and the
+// read position is checkpointed, so that records survive process restarts
+// and are forwarded at least once. If the downstream writer is a [Flusher],
+// such as a [Sink], records are only acknowledged once it has delivered
+// them; otherwise they are acknowledged when Write returns. Records of a
+// batch that the downstream writer fails to deliver are forwarded again.
+// When the total size exceeds the limit the oldest segment is evicted and a
+// warning is logged. The rest of a segment with a corrupt record is skipped,
+// also with a warning.
+type DiskBuffer struct {
+	dir  string
+	o    DiskBufferOptions
+	down io.Writer
+
+	mu       sync.Mutex
+	active   *os.File
+	segments []uint64         // segments are the sequence numbers on disk, oldest first.
+	sizes    map[uint64]int64 // sizes are the segment sizes by sequence number.
+	total    int64
+	evicted  []string // evicted are the segments evicted since the last warning.
+	nevicted uint64
+	closed   bool
+
+	// The read position is owned by the forwarder.
+	readSeq, ckSeq uint64
+	readOff, ckOff int64
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+var _ io.WriteCloser = (*DiskBuffer)(nil)
+
+// NewDiskBuffer returns a new disk buffer storing segments in dir and
+// forwarding records to the downstream writer. Records left in dir by a
+// previous process are forwarded first.
+func NewDiskBuffer(dir string, downstream io.Writer, opts ...DiskBufferOption) (*DiskBuffer, error) {
+	o := DiskBufferOptions{
+		SegmentSize:   4 << 20,
+		MaxSize:       256 << 20,
+		RetryInterval: time.Second,
+		BatchSize:     100,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch {
+	case o.SegmentSize <= 0:
+		return nil, fmt.Errorf("log: invalid disk buffer segment size %d", o.SegmentSize)
+	case o.MaxSize <= 0:
+		return nil, fmt.Errorf("log: invalid disk buffer max size %d", o.MaxSize)
+	case o.RetryInterval <= 0:
+		return nil, fmt.Errorf("log: invalid disk buffer retry interval %s", o.RetryInterval)
+	case o.BatchSize <= 0:
+		return nil, fmt.Errorf("log: invalid disk buffer batch size %d", o.BatchSize)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("log: create disk buffer: %w", err)
+	}
+
+	b := &DiskBuffer{
+		dir:    dir,
+		o:      o,
+		down:   downstream,
+		sizes:  make(map[uint64]int64),
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if err := b.load(); err != nil {
+		return nil, err
+	}
+	// Never append to a segment from a previous process, whose tail may be
+	// torn.
+	if err := b.rotate(b.nextSeq()); err != nil {
+		return nil, err
+	}
+
+	go b.run()
+	return b, nil
+}
+
+// load reads the existing segments and the checkpoint.
+func (b *DiskBuffer) load() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return fmt.Errorf("log: read disk buffer: %w", err)
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return fmt.Errorf("log: read disk buffer: %w", err)
+		}
+		b.segments = append(b.segments, seq)
+		b.sizes[seq] = info.Size()
+		b.total += info.Size()
+	}
+	slices.Sort(b.segments)
+
+	if data, err := os.ReadFile(filepath.Join(b.dir, checkpointFile)); err == nil {
+		_, _ = fmt.Sscanf(string(data), "%d %d", &b.readSeq, &b.readOff)
+	}
+	switch n := len(b.segments); {
+	case n == 0 || b.readSeq > b.segments[n-1]:
+		// The segments of the checkpoint are gone, so reading starts with
+		// the segment that will be created next.
+		b.readSeq, b.readOff = b.nextSeq(), 0
+	case b.readSeq < b.segments[0]:
+		b.readSeq, b.readOff = b.segments[0], 0
+	}
+	b.ckSeq, b.ckOff = b.readSeq, b.readOff
+	return nil
+}
+
+// nextSeq returns the sequence number of the segment created after the
+// existing ones.
+func (b *DiskBuffer) nextSeq() uint64 {
+	if n := len(b.segments); n > 0 {
+		return b.segments[n-1] + 1
+	}
+	return 1
+}
+
+// Write appends a copy of p to the active segment as one record. Records
+// that do not fit in a segment are rejected.
+func (b *DiskBuffer) Write(p []byte) (int, error) {
+	if int64(len(p)) > b.o.SegmentSize-frameHeaderLen {
+		return 0, fmt.Errorf("log: record of %d bytes exceeds the disk buffer segment size", len(p))
+	}
+	frame := make([]byte, frameHeaderLen+len(p))
+	binary.BigEndian.PutUint32(frame, uint32(len(p)))
+	copy(frame[frameHeaderLen:], p)
+	n := int64(len(frame))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, ErrDiskBufferClosed
+	}
+
+	seq := b.segments[len(b.segments)-1]
+	if b.sizes[seq] > 0 && b.sizes[seq]+n > b.o.SegmentSize {
+		if err := b.rotate(seq + 1); err != nil {
+			return 0, err
+		}
+		seq++
+	}
+	b.evict(n)
+
+	if _, err := b.active.Write(frame); err != nil {
+		return 0, fmt.Errorf("log: write disk buffer: %w", err)
+	}
+	if b.o.Sync {
+		if err := b.active.Sync(); err != nil {
+			return 0, fmt.Errorf("log: sync disk buffer: %w", err)
+		}
+	}
+	b.sizes[seq] += n
+	b.total += n
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// rotate closes the active segment and creates the segment seq. It must be
+// called with the lock held.
+func (b *DiskBuffer) rotate(seq uint64) error {
+	f, err := os.OpenFile(b.segmentPath(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("log: create segment: %w", err)
+	}
+	if b.active != nil {
+		b.active.Close()
+	}
+	b.active = f
+	b.segments = append(b.segments, seq)
+	b.sizes[seq] = 0
+	return nil
+}
+
+// evict removes the oldest inactive segments until n more bytes fit within
+// the size limit. It must be called with the lock held.
+func (b *DiskBuffer) evict(n int64) {
+	for b.total+n > b.o.MaxSize && len(b.segments) > 1 {
+		seq := b.segments[0]
+		if err := os.Remove(b.segmentPath(seq)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+		b.segments = b.segments[1:]
+		b.total -= b.sizes[seq]
+		delete(b.sizes, seq)
+		b.evicted = append(b.evicted, filepath.Base(b.segmentPath(seq)))
+		b.nevicted++
+	}
+}
+
+// Close forwards the buffered records that the downstream writer accepts,
+// then stops the buffer. Records that could not be forwarded remain on disk.
+func (b *DiskBuffer) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.active.Close()
+}
+
+// Stats returns the buffer counters.
+func (b *DiskBuffer) Stats() DiskBufferStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return DiskBufferStats{
+		Segments: len(b.segments),
+		Bytes:    b.total,
+		Evicted:  b.nevicted,
+	}
+}
+
+// run forwards records until the buffer is closed.
+func (b *DiskBuffer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.o.RetryInterval)
+	defer ticker.Stop()
+	for {
+		b.warn()
+		b.forward()
+		select {
+		case <-b.notify:
+		case <-ticker.C:
+		case <-b.stop:
+			b.forward()
+			b.warn()
+			return
+		}
+	}
+}
+
+// warn logs a warning for each segment evicted since the last call.
+func (b *DiskBuffer) warn() {
+	b.mu.Lock()
+	evicted := b.evicted
+	b.evicted = nil
+	b.mu.Unlock()
+
+	for _, name := range evicted {
+		b.logger().Warn("log: disk buffer full, evicted oldest segment", "segment", name)
+	}
+}
+
+// logger returns the logger of the warnings.
+func (b *DiskBuffer) logger() *slog.Logger {
+	if b.o.Logger != nil {
+		return b.o.Logger
+	}
+	return Default()
+}
+
+// rotateActive creates a new active segment if seq is still the active one.
+func (b *DiskBuffer) rotateActive(seq uint64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed || b.segments[len(b.segments)-1] != seq {
+		return nil
+	}
+	return b.rotate(seq + 1)
+}
+
+// forward sends the records after the read position to the downstream
+// writer, deleting the segments that have been fully forwarded. It stops at
+// the first downstream error.
+func (b *DiskBuffer) forward() {
+	for {
+		b.mu.Lock()
+		segments := slices.Clone(b.segments)
+		b.mu.Unlock()
+
+		i, found := slices.BinarySearch(segments, b.readSeq)
+		if !found {
+			if i == len(segments) {
+				return
+			}
+			// The segment was evicted.
+			b.readSeq, b.readOff = segments[i], 0
+		}
+		active := i == len(segments)-1
+
+		done, err := b.forwardSegment(b.readSeq)
+		if errors.Is(err, errCorruptSegment) {
+			// The rest of the segment is unreadable: skip it, and stop
+			// appending to it.
+			b.logger().Warn("log: disk buffer segment corrupt, skipped its remaining records",
+				"segment", filepath.Base(b.segmentPath(b.readSeq)))
+			err = nil
+			if active {
+				err = b.rotateActive(b.readSeq)
+				active = false
+			}
+		}
+		b.checkpoint()
+		if err != nil || !done || active {
+			return
+		}
+
+		b.mu.Lock()
+		if seq := b.readSeq; len(b.segments) > 0 && b.segments[0] == seq {
+			if err := os.Remove(b.segmentPath(seq)); err == nil || errors.Is(err, fs.ErrNotExist) {
+				b.segments = b.segments[1:]
+				b.total -= b.sizes[seq]
+				delete(b.sizes, seq)
+			}
+		}
+		b.mu.Unlock()
+		b.readSeq, b.readOff = b.readSeq+1, 0
+		b.checkpoint()
+	}
+}
+
+// forwardSegment forwards the records of the segment after the read
+// offset. It reports whether the end of the segment was reached.
+func (b *DiskBuffer) forwardSegment(seq uint64) (bool, error) {
+	f, err := os.Open(b.segmentPath(seq))
+	if err != nil {
+		return errors.Is(err, fs.ErrNotExist), err
+	}
+	defer f.Close()
+	if _, err := f.Seek(b.readOff, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	flusher, _ := b.down.(Flusher)
+	off, pending := b.readOff, 0
+	// ack advances the read position to the forwarded records, once the
+	// downstream writer has delivered them.
+	ack := func() error {
+		if flusher != nil && pending > 0 {
+			if err := flusher.Flush(); err != nil {
+				return err
+			}
+		}
+		b.readOff, pending = off, 0
+		return nil
+	}
+
+	r := bufio.NewReader(f)
+	var header [frameHeaderLen]byte
+	for {
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			// An incomplete frame is either still being written or a torn
+			// tail; both end the readable part of the segment.
+			return true, ack()
+		}
+		n := int64(binary.BigEndian.Uint32(header[:]))
+		if n > b.o.SegmentSize-frameHeaderLen {
+			// No valid record is larger than a segment.
+			if err := ack(); err != nil {
+				return false, err
+			}
+			return true, errCorruptSegment
+		}
+		rec := make([]byte, n)
+		if _, err := io.ReadFull(r, rec); err != nil {
+			return true, ack()
+		}
+		if _, err := b.down.Write(rec); err != nil {
+			return false, err
+		}
+		off += int64(frameHeaderLen + len(rec))
+		pending++
+		if flusher == nil || pending >= b.o.BatchSize {
+			if err := ack(); err != nil {
+				return false, err
+			}
+		}
+	}
+}
+
+// checkpoint persists the read position if it changed.
+func (b *DiskBuffer) checkpoint() {
+	if b.readSeq == b.ckSeq && b.readOff == b.ckOff {
+		return
+	}
+	b.ckSeq, b.ckOff = b.readSeq, b.readOff
+	path := filepath.Join(b.dir, checkpointFile)
+	tmp := path + ".tmp"
+	data := fmt.Sprintf("%d %d\n", b.readSeq, b.readOff)
+	if err := os.WriteFile(tmp, []byte(data), 0o644); err == nil {
+		_ = os.Rename(tmp, path)
+	}
+}
+
+// segmentPath returns the path of the segment seq.
+func (b *DiskBuffer) segmentPath(seq uint64) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", seq, segmentExt))
+}
@@ -0,0 +1,265 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder is a downstream writer that records each write and can be made
+// to fail.
+type recorder struct {
+	mu   sync.Mutex
+	recs []string
+	fail bool
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		return 0, errors.New("unavailable")
+	}
+	r.recs = append(r.recs, string(p))
+	return len(p), nil
+}
+
+func (r *recorder) records() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.recs...)
+}
+
+func TestDiskBuffer(t *testing.T) {
+	dir := t.TempDir()
+	down := &recorder{}
+	buf, err := log.NewDiskBuffer(dir, down, func(o *log.DiskBufferOptions) {
+		o.SegmentSize = 64
+		o.RetryInterval = 10 * time.Millisecond
+	})
+	require.NoError(t, err)
+
+	logger := log.New(log.UseOutput(buf), log.UseFormatter(log.LogfmtFormatter))
+	for _, msg := range []string{"one", "two", "three", "four"} {
+		logger.Info(msg)
+	}
+
+	assert.Eventually(t, func() bool { return len(down.records()) == 4 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, "level=info msg=one\n", down.records()[0])
+	require.NoError(t, buf.Close())
+
+	// Forwarded segments are deleted, except the last active one.
+	segments, err := filepath.Glob(filepath.Join(dir, "*.seg"))
+	require.NoError(t, err)
+	assert.Len(t, segments, 1)
+}
+
+func TestDiskBufferRestart(t *testing.T) {
+	dir := t.TempDir()
+	down := &recorder{fail: true}
+	opt := func(o *log.DiskBufferOptions) { o.RetryInterval = 10 * time.Millisecond }
+
+	buf, err := log.NewDiskBuffer(dir, down, opt)
+	require.NoError(t, err)
+	_, err = buf.Write([]byte("one\n"))
+	require.NoError(t, err)
+	_, err = buf.Write([]byte("two\n"))
+	require.NoError(t, err)
+	require.NoError(t, buf.Close())
+	assert.Empty(t, down.records())
+
+	down.fail = false
+	buf, err = log.NewDiskBuffer(dir, down, opt)
+	require.NoError(t, err)
+	_, err = buf.Write([]byte("three\n"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(down.records()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, buf.Close())
+	assert.Equal(t, []string{"one\n", "two\n", "three\n"}, down.records())
+
+	// Records are not forwarded twice after a restart.
+	buf, err = log.NewDiskBuffer(dir, down, opt)
+	require.NoError(t, err)
+	require.NoError(t, buf.Close())
+	assert.Len(t, down.records(), 3)
+}
+
+func TestDiskBufferEviction(t *testing.T) {
+	dir := t.TempDir()
+	down := &recorder{fail: true}
+	var warnings bytes.Buffer
+	buf, err := log.NewDiskBuffer(dir, down, func(o *log.DiskBufferOptions) {
+		o.SegmentSize = 16
+		o.MaxSize = 32
+		o.RetryInterval = 10 * time.Millisecond
+		o.Logger = log.New(log.UseOutput(&warnings))
+	})
+	require.NoError(t, err)
+
+	for _, rec := range []string{"one\n", "two\n", "three\n", "four\n", "five\n"} {
+		_, err := buf.Write([]byte(rec))
+		require.NoError(t, err)
+	}
+	stats := buf.Stats()
+	assert.LessOrEqual(t, stats.Bytes, int64(32))
+	assert.Positive(t, stats.Evicted)
+	require.NoError(t, buf.Close())
+	assert.Contains(t, warnings.String(), "evicted oldest segment")
+
+	down.mu.Lock()
+	down.fail = false
+	down.mu.Unlock()
+	buf, err = log.NewDiskBuffer(dir, down, func(o *log.DiskBufferOptions) {
+		o.RetryInterval = 10 * time.Millisecond
+	})
+	require.NoError(t, err)
+	require.NoError(t, buf.Close())
+	recs := down.records()
+	require.NotEmpty(t, recs)
+	assert.Equal(t, "five\n", recs[len(recs)-1])
+	assert.NotContains(t, strings.Join(recs, ""), "one")
+}
+
+func TestDiskBufferStaleCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	// A checkpoint whose segments were removed.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checkpoint"), []byte("5 10\n"), 0o644))
+
+	down := &recorder{}
+	buf, err := log.NewDiskBuffer(dir, down, func(o *log.DiskBufferOptions) { o.RetryInterval = 10 * time.Millisecond })
+	require.NoError(t, err)
+	_, err = buf.Write([]byte("one\n"))
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(down.records()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, buf.Close())
+}
+
+func TestDiskBufferCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	frame := func(rec string, n uint32) []byte {
+		return append(binary.BigEndian.AppendUint32(nil, n), rec...)
+	}
+	// The second frame of the first segment has a corrupt length.
+	seg1 := append(frame("one\n", 4), frame("two\n", 1<<31)...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000001.seg"), seg1, 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000002.seg"), frame("three\n", 6), 0o644))
+
+	down := &recorder{}
+	var warnings bytes.Buffer
+	buf, err := log.NewDiskBuffer(dir, down, func(o *log.DiskBufferOptions) {
+		o.SegmentSize = 64
+		o.RetryInterval = 10 * time.Millisecond
+		o.Logger = log.New(log.UseOutput(&warnings))
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(down.records()) == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	_, err = buf.Write(make([]byte, 61))
+	assert.Error(t, err)
+	require.NoError(t, buf.Close())
+	assert.Equal(t, []string{"one\n", "three\n"}, down.records())
+	assert.Contains(t, warnings.String(), "segment corrupt")
+}
+
+func TestDiskBufferClosed(t *testing.T) {
+	buf, err := log.NewDiskBuffer(t.TempDir(), &recorder{})
+	require.NoError(t, err)
+	require.NoError(t, buf.Close())
+	_, err = buf.Write([]byte("test message"))
+	assert.ErrorIs(t, err, log.ErrDiskBufferClosed)
+}
+
+func TestDiskBufferSink(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []string
+		outage   atomic.Bool
+		failures atomic.Int32
+	)
+	outage.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if outage.Load() {
+			failures.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		mu.Lock()
+		received = append(received, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink, err := log.NewSink(srv.URL, func(o *log.SinkOptions) {
+		o.BatchSize = 2
+		o.BufferSize = 2
+		o.FlushInterval = 10 * time.Millisecond
+		o.MaxRetries = 0
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+	buf, err := log.NewDiskBuffer(t.TempDir(), sink, func(o *log.DiskBufferOptions) {
+		o.RetryInterval = 10 * time.Millisecond
+		o.BatchSize = 2
+	})
+	require.NoError(t, err)
+
+	logger := log.New(log.UseOutput(buf), log.UseFormatter(log.LogfmtFormatter))
+	want := []string{"one", "two", "three", "four", "five"}
+	for _, msg := range want {
+		logger.Info(msg)
+	}
+	assert.Eventually(t, func() bool { return failures.Load() >= 3 }, 5*time.Second, time.Millisecond)
+	outage.Store(false)
+
+	// Every record is delivered at least once, despite the records that the
+	// sink discarded during the outage.
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, msg := range want {
+			if !slices.Contains(received, "level=info msg="+msg) {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, buf.Close())
+	assert.Positive(t, sink.Stats().Failed)
+}
+
+func TestNewDiskBuffer(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  log.DiskBufferOption
+	}{
+		{"ZeroSegmentSize", func(o *log.DiskBufferOptions) { o.SegmentSize = 0 }},
+		{"ZeroMaxSize", func(o *log.DiskBufferOptions) { o.MaxSize = 0 }},
+		{"ZeroRetryInterval", func(o *log.DiskBufferOptions) { o.RetryInterval = 0 }},
+		{"NegativeRetryInterval", func(o *log.DiskBufferOptions) { o.RetryInterval = -time.Second }},
+		{"ZeroBatchSize", func(o *log.DiskBufferOptions) { o.BatchSize = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := log.NewDiskBuffer(t.TempDir(), &recorder{}, tt.opt)
+			assert.Error(t, err)
+			assert.Nil(t, buf)
+		})
+	}
+}
@@ -0,0 +1,712 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
+)
+
+// noLevel is the level used by [Print]. It is always enabled and
+// never rendered.
+const noLevel Level = 1<<31 - 1
+
+// fileRenderers caches the lipgloss renderers of files by descriptor, so
+// that a terminal is only queried once. Other writers get a renderer per
+// handler, since they may not be comparable and must not be kept alive.
+var fileRenderers sync.Map // map[fileKey]*lipgloss.Renderer
+
+// fileKey identifies a file of [fileRenderers].
+type fileKey struct {
+	fd   uintptr
+	name string
+}
+
+// newRenderer returns the lipgloss renderer of w.
+func newRenderer(w io.Writer) *lipgloss.Renderer {
+	f, ok := w.(*os.File)
+	if !ok {
+		return lipgloss.NewRenderer(w, termenv.WithColorCache(true))
+	}
+	key := fileKey{f.Fd(), f.Name()}
+	if re, ok := fileRenderers.Load(key); ok {
+		return re.(*lipgloss.Renderer)
+	}
+	re, _ := fileRenderers.LoadOrStore(key, lipgloss.NewRenderer(f, termenv.WithColorCache(true)))
+	return re.(*lipgloss.Renderer)
+}
+
+// handler is a [slog.Handler] that writes records using the text, JSON or
+// logfmt formatter.
+type handler struct {
+	mu *sync.RWMutex
+	w  io.Writer
+	re *lipgloss.Renderer
+
+	isDiscard uint32
+	level     int32
+	leveler   atomic.Pointer[levelerRef] // leveler, if set, replaces level.
+	overrides atomic.Pointer[levelOverrides]
+
+	prefix          string
+	timeFunc        TimeFunction
+	timeFormat      string
+	timeMode        TimeMode
+	timeLocation    *time.Location
+	clock           *clock
+	callerOffset    int
+	callerFormatter CallerFormatter
+	callerLink      string
+	callerFields    bool
+	hyperlinks      bool // hyperlinks is whether the output supports hyperlinks.
+	formatter       Formatter
+	auto            bool // auto is whether the formatter is selected by the output.
+	reportCaller    bool
+	reportTimestamp bool
+	styles          *Styles
+	autoTheme       bool // autoTheme is whether the styles are resolved from [ThemeAuto] by the output.
+	keys            Keys
+	replaceAttr     ReplaceAttrFunc
+	metrics         *Metrics
+	hooks           []Hook
+	onError         ErrorHandler
+	ring            *Ring
+	flight          *Level // flight is the minimum level held in flight recorder scopes.
+	dedup           *dedup
+	rules           []Rule
+	pretty          *PrettyOptions
+	sanitize        bool
+	levelLabels     *LevelLabels
+	columns         *Columns
+	termWidth       int // termWidth is the width of the output terminal, or 0.
+
+	attrs  []slog.Attr // attrs are the group-qualified logger attributes.
+	groups []string    // groups are the open groups.
+}
+
+var _ slog.Handler = (*handler)(nil)
+
+// newHandler returns a new handler for the given options.
+func newHandler(o *Options) *handler {
+	lo := o.LogOptions
+	if lo == nil {
+		lo = &LogOptions{}
+	}
+	h := &handler{
+		mu:              &sync.RWMutex{},
+		level:           int32(lo.Level),
+		prefix:          lo.Prefix,
+		timeFunc:        lo.TimeFunction,
+		timeFormat:      lo.TimeFormat,
+		timeMode:        o.TimeMode,
+		timeLocation:    o.TimeLocation,
+		clock:           newClock(),
+		callerOffset:    lo.CallerOffset,
+		callerFormatter: lo.CallerFormatter,
+		callerLink:      o.CallerLink,
+		callerFields:    o.CallerFields,
+		formatter:       lo.Formatter,
+		auto:            lo.Formatter == AutoFormatter,
+		reportCaller:    lo.ReportCaller,
+		reportTimestamp: lo.ReportTimestamp,
+		styles:          handlerStyles(o.Styles),
+		keys:            o.Keys,
+		replaceAttr:     o.ReplaceAttr,
+		metrics:         o.Metrics,
+		hooks:           slices.Clone(o.Hooks),
+		onError:         o.ErrorHandler,
+		ring:            o.Ring,
+		flight:          o.FlightRecorder,
+		rules:           slices.Clone(o.Rules),
+		pretty:          o.Pretty,
+		sanitize:        o.Sanitize,
+		levelLabels:     o.LevelLabels.clone(),
+		columns:         o.Columns,
+	}
+	h.overrides.Store(newLevelOverrides(o.LevelOverrides))
+	if o.Leveler != nil {
+		h.leveler.Store(&levelerRef{o.Leveler})
+	}
+	if o.Dedup > 0 {
+		h.dedup = newDedup(o.Dedup)
+	}
+	if o.Theme != nil {
+		h.styles = o.Theme.Styles()
+		h.autoTheme = o.Theme.Name == ThemeAuto
+	}
+	h.SetOutput(o.Writer)
+
+	if h.callerFormatter == nil {
+		h.callerFormatter = ShortCallerFormatter
+	}
+	if h.timeFunc == nil {
+		h.timeFunc = func(t time.Time) time.Time { return t }
+	}
+	if h.timeFormat == "" {
+		h.timeFormat = log.DefaultTimeFormat
+	}
+	if h.onError == nil {
+		h.onError = defaultErrorHandler
+	}
+	if len(lo.Fields) > 0 {
+		h.attrs = h.appendAttrs(nil, nil, slog.Group("", lo.Fields...).Value.Group()...)
+	}
+
+	return h
+}
+
+//  +------------------------------------------------------------+
+//  | slog.Handler 											 	 |
+//  +------------------------------------------------------------+
+
+// Enabled reports whether the handler handles records at the given level.
+// Rejected levels are counted as filtered by the metrics.
+func (h *handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.handles(ctx, Level(level)) {
+		return true
+	}
+	h.countFiltered(Level(level))
+	return false
+}
+
+// handles reports whether records at the given level are written, held
+// or may be raised by the rules.
+func (h *handler) handles(ctx context.Context, level Level) bool {
+	if h.enabled(level) {
+		return true
+	}
+	if lo := h.overrides.Load(); lo != nil && level >= lo.min {
+		return true
+	}
+	if h.ring != nil && h.ring.enabled(level) {
+		return true
+	}
+	return h.recording(ctx, level) || h.rulesHandle(level)
+}
+
+// enabled reports whether records at the given level are written to the
+// output.
+func (h *handler) enabled(level Level) bool {
+	return atomic.LoadUint32(&h.isDiscard) == 0 &&
+		level >= h.minLevel()
+}
+
+// enabledAt reports whether records at the given level logged from pc are
+// written to the output, applying the level overrides.
+func (h *handler) enabledAt(level Level, pc uintptr) bool {
+	if lo := h.overrides.Load(); lo != nil {
+		if l, ok := lo.level(pc); ok {
+			return atomic.LoadUint32(&h.isDiscard) == 0 && level >= l
+		}
+	}
+	return h.enabled(level)
+}
+
+// Handle formats the record and writes it to the output.
+// Routed records are written to their route instead of the output.
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if offset := h.CallerOffset(); offset != 0 && r.PC != 0 {
+		r.PC = callerPC(r.PC, offset)
+	}
+	// Resolve the values once, for the rules, the ring, the hooks and the
+	// formatter.
+	r = resolveRecord(r)
+	var (
+		route     io.Writer
+		routeRule *Rule
+	)
+	if len(h.rules) > 0 && h.handles(ctx, Level(r.Level)) {
+		var drop bool
+		if r, routeRule, drop = h.applyRules(r); drop {
+			return nil
+		}
+	}
+	if h.ring != nil && h.ring.enabled(Level(r.Level)) {
+		h.ring.add(h.ringRecord(r))
+	}
+	var enabled bool
+	if routeRule != nil {
+		route = routeRule.Route
+		enabled = h.routeEnabled(routeRule, Level(r.Level), r.PC)
+	} else {
+		enabled = h.enabledAt(Level(r.Level), r.PC)
+	}
+	if !enabled {
+		if h.recording(ctx, Level(r.Level)) {
+			scopeFromContext(ctx).add(h, route, r)
+			return nil
+		}
+		h.countFiltered(Level(r.Level))
+		return nil
+	}
+
+	if Level(r.Level) >= ErrorLevel {
+		if s := scopeFromContext(ctx); s != nil {
+			for _, sr := range s.drain() {
+				_ = sr.h.write(ctx, sr.w, sr.r)
+			}
+		}
+	}
+
+	return h.write(ctx, route, r)
+}
+
+// callerPC returns the program counter offset frames above pc in the
+// current stack, or pc if there is no such frame, for example when the
+// record is handled after the logging call returned.
+func callerPC(pc uintptr, offset int) uintptr {
+	var pcs [64]uintptr
+	n := runtime.Callers(2, pcs[:])
+	for i, p := range pcs[:n] {
+		if p == pc {
+			if j := i + offset; j >= 0 && j < n {
+				return pcs[j]
+			}
+			break
+		}
+	}
+	return pc
+}
+
+// countFiltered counts a record filtered out by the level.
+func (h *handler) countFiltered(level Level) {
+	if h.metrics != nil && atomic.LoadUint32(&h.isDiscard) == 0 {
+		h.metrics.record(level, h.GetPrefix(), false)
+	}
+}
+
+// write writes the record to w, or to the output if w is nil, unless it
+// repeats the previous record.
+func (h *handler) write(ctx context.Context, w io.Writer, r slog.Record) error {
+	if h.dedup != nil && h.dedup.suppress(ctx, h, w, r) {
+		return nil
+	}
+	return h.output(ctx, w, r)
+}
+
+// output runs the hooks, then formats the record and writes it to w, or to
+// the output if w is nil.
+func (h *handler) output(ctx context.Context, w io.Writer, r slog.Record) error {
+	r, qualified := h.fireHooks(ctx, r)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.metrics != nil {
+		h.metrics.record(Level(r.Level), h.prefix, true)
+	}
+
+	e := h.entry(r, qualified)
+
+	b := newBuffer()
+	defer freeBuffer(b)
+	switch h.formatter {
+	case LogfmtFormatter:
+		h.logfmtFormatter(b, e)
+	case JSONFormatter:
+		h.jsonFormatter(b, e)
+	default:
+		h.textFormatter(b, e)
+	}
+
+	if w == nil {
+		w = h.w
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// maxBufferSize is the capacity above which buffers are not reused, so
+// that a single large record does not pin memory.
+const maxBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// newBuffer returns an empty buffer from the pool.
+func newBuffer() *bytes.Buffer {
+	b := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// freeBuffer returns the buffer to the pool.
+func freeBuffer(b *bytes.Buffer) {
+	if b.Cap() <= maxBufferSize {
+		bufferPool.Put(b)
+	}
+}
+
+// WithAttrs returns a new handler with the given attributes added.
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := h.clone()
+	h2.attrs = h2.appendAttrs(h2.attrs, h2.groups, attrs...)
+	return h2
+}
+
+// WithGroup returns a new handler with the given group opened. The keys of
+// subsequent attributes are qualified by the group name.
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := h.clone()
+	h2.groups = append(h2.groups[:len(h2.groups):len(h2.groups)], name)
+	return h2
+}
+
+// clone returns a copy of the handler with its own lock.
+func (h *handler) clone() *handler {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	h2 := &handler{
+		mu:              &sync.RWMutex{},
+		w:               h.w,
+		re:              h.re,
+		isDiscard:       atomic.LoadUint32(&h.isDiscard),
+		level:           atomic.LoadInt32(&h.level),
+		prefix:          h.prefix,
+		timeFunc:        h.timeFunc,
+		timeFormat:      h.timeFormat,
+		timeMode:        h.timeMode,
+		timeLocation:    h.timeLocation,
+		clock:           h.clock,
+		callerOffset:    h.callerOffset,
+		callerFormatter: h.callerFormatter,
+		callerLink:      h.callerLink,
+		callerFields:    h.callerFields,
+		hyperlinks:      h.hyperlinks,
+		formatter:       h.formatter,
+		auto:            h.auto,
+		reportCaller:    h.reportCaller,
+		reportTimestamp: h.reportTimestamp,
+		styles:          h.styles,
+		autoTheme:       h.autoTheme,
+		keys:            h.keys,
+		replaceAttr:     h.replaceAttr,
+		metrics:         h.metrics,
+		hooks:           h.hooks[:len(h.hooks):len(h.hooks)],
+		onError:         h.onError,
+		ring:            h.ring,
+		flight:          h.flight,
+		dedup:           h.dedup,
+		rules:           h.rules,
+		pretty:          h.pretty,
+		sanitize:        h.sanitize,
+		levelLabels:     h.levelLabels,
+		columns:         h.columns,
+		termWidth:       h.termWidth,
+		attrs:           h.attrs[:len(h.attrs):len(h.attrs)],
+		groups:          h.groups,
+	}
+	h2.overrides.Store(h.overrides.Load())
+	h2.leveler.Store(h.leveler.Load())
+	return h2
+}
+
+//  +------------------------------------------------------------+
+//  | Entries 												 	 |
+//  +------------------------------------------------------------+
+
+// entry is a record prepared for formatting. Built-in fields with an empty
+// key are omitted from the output.
+type entry struct {
+	level   Level
+	time    slog.Attr
+	lvl     slog.Attr
+	caller  slog.Attr
+	prefix  slog.Attr
+	message slog.Attr
+	attrs   []slog.Attr
+	frame   runtime.Frame // frame is the caller frame, if the caller is reported.
+}
+
+// entry prepares the record for formatting. The attributes of a qualified
+// record already include the logger attributes, see [handler.fireHooks]. It
+// must be called with the lock held.
+func (h *handler) entry(r slog.Record, qualified bool) entry {
+	e := entry{level: Level(r.Level)}
+
+	if h.reportTimestamp && !r.Time.IsZero() {
+		e.time = h.timestamp(r.Time)
+	}
+	if h.hasLevel(e.level) {
+		e.lvl = slog.Any(h.keys.Level, e.level)
+	}
+	if h.reportCaller && r.PC != 0 {
+		frames := runtime.CallersFrames([]uintptr{r.PC})
+		f, _ := frames.Next()
+		if f.File != "" {
+			e.frame = f
+			e.caller = slog.String(h.keys.Caller, h.callerFormatter(f.File, f.Line, f.Function))
+		}
+	}
+	if h.prefix != "" {
+		e.prefix = slog.String(h.keys.Prefix, h.prefix)
+	}
+	if r.Message != "" {
+		e.message = slog.String(h.keys.Message, r.Message)
+	}
+	if h.replaceAttr != nil {
+		for _, a := range []*slog.Attr{&e.time, &e.lvl, &e.caller, &e.prefix, &e.message} {
+			if a.Key != "" {
+				*a = h.replaceAttr(nil, *a)
+				a.Value = a.Value.Resolve()
+			}
+		}
+	}
+
+	if qualified {
+		e.attrs = make([]slog.Attr, 0, r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			e.attrs = flattenAttrs(e.attrs, nil, a)
+			return true
+		})
+		return e
+	}
+	e.attrs = make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs())
+	e.attrs = append(e.attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		e.attrs = h.appendAttrs(e.attrs, h.groups, a)
+		return true
+	})
+
+	return e
+}
+
+// appendAttrs appends the given attributes to dst, resolving their values,
+// applying the replace function and flattening groups into dot-separated
+// keys.
+func (h *handler) appendAttrs(dst []slog.Attr, groups []string, attrs ...slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		if a.Equal(slog.Attr{}) {
+			continue
+		}
+		if h.replaceAttr != nil && a.Value.Kind() != slog.KindGroup {
+			a = h.replaceAttr(groups, a)
+			a.Value = a.Value.Resolve()
+			if a.Key == "" {
+				continue
+			}
+		}
+		if a.Value.Kind() == slog.KindGroup {
+			g := groups
+			if a.Key != "" {
+				g = append(groups[:len(groups):len(groups)], a.Key)
+			}
+			dst = h.appendAttrs(dst, g, a.Value.Group()...)
+			continue
+		}
+		if len(groups) > 0 {
+			a.Key = strings.Join(groups, ".") + "." + a.Key
+		}
+		dst = append(dst, a)
+	}
+	return dst
+}
+
+//  +------------------------------------------------------------+
+//  | Setters 												 	 |
+//  +------------------------------------------------------------+
+
+// SetLevel sets the level, replacing the leveler.
+func (h *handler) SetLevel(level Level) {
+	atomic.StoreInt32(&h.level, int32(level))
+	h.leveler.Store(nil)
+}
+
+// SetLeveler sets the leveler. A nil leveler restores the level.
+func (h *handler) SetLeveler(l slog.Leveler) {
+	if l == nil {
+		h.leveler.Store(nil)
+		return
+	}
+	h.leveler.Store(&levelerRef{l})
+}
+
+// SetLevelOverrides sets the level overrides.
+func (h *handler) SetLevelOverrides(overrides []LevelOverride) {
+	h.overrides.Store(newLevelOverrides(overrides))
+}
+
+// GetLevel returns the level.
+func (h *handler) GetLevel() Level {
+	return h.minLevel()
+}
+
+// SetPrefix sets the prefix.
+func (h *handler) SetPrefix(prefix string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prefix = prefix
+}
+
+// GetPrefix returns the prefix.
+func (h *handler) GetPrefix() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.prefix
+}
+
+// SetTimeFormat sets the time format.
+func (h *handler) SetTimeFormat(format string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeFormat = format
+}
+
+// SetTimeFunction sets the time function.
+func (h *handler) SetTimeFunction(f TimeFunction) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeFunc = f
+}
+
+// SetTimeMode sets the time mode.
+func (h *handler) SetTimeMode(m TimeMode) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeMode = m
+}
+
+// SetTimeLocation sets the time location.
+func (h *handler) SetTimeLocation(loc *time.Location) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.timeLocation = loc
+}
+
+// SetOutput sets the output destination.
+func (h *handler) SetOutput(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if w == nil {
+		w = os.Stderr
+	}
+	h.w = w
+	var isDiscard uint32
+	if w == io.Discard {
+		isDiscard = 1
+	}
+	atomic.StoreUint32(&h.isDiscard, isDiscard)
+	h.re = newRenderer(w)
+	if h.auto {
+		h.formatter, h.re = detectOutput(w, h.re)
+	}
+	h.termWidth = terminalWidth(w)
+	h.hyperlinks = supportsHyperlinks(w)
+	if h.autoTheme {
+		h.styles = themes[ThemeAuto].resolve(h.re).Styles()
+	}
+}
+
+// GetOutput returns the output.
+func (h *handler) GetOutput() io.Writer {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.w
+}
+
+// SetFormatter sets the formatter.
+func (h *handler) SetFormatter(f Formatter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.formatter = f
+	h.auto = f == AutoFormatter
+	if h.auto {
+		h.formatter, h.re = detectOutput(h.w, h.re)
+	}
+}
+
+// SetCallerFormatter sets the caller formatter.
+func (h *handler) SetCallerFormatter(f CallerFormatter) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callerFormatter = f
+}
+
+// SetCallerOffset sets the caller offset.
+func (h *handler) SetCallerOffset(offset int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callerOffset = offset
+}
+
+// CallerOffset returns the caller offset.
+func (h *handler) CallerOffset() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.callerOffset
+}
+
+// SetStyles sets the styles for the text formatter, replacing the theme.
+func (h *handler) SetStyles(s *Styles) {
+	s = handlerStyles(s)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.styles = s
+	h.autoTheme = false
+}
+
+// SetReportCaller sets whether to report the caller location.
+func (h *handler) SetReportCaller(report bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reportCaller = report
+}
+
+// SetReportTimestamp sets whether to report the timestamp.
+func (h *handler) SetReportTimestamp(report bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.reportTimestamp = report
+}
+
+// AddHook adds a hook.
+func (h *handler) AddHook(hook Hook) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks[:len(h.hooks):len(h.hooks)], hook)
+}
+
+// Flush writes the summary record of the current run of repeats, if any.
+func (h *handler) Flush() {
+	if h.dedup != nil {
+		h.dedup.flush()
+	}
+}
+
+// SetErrorHandler sets the error handler.
+func (h *handler) SetErrorHandler(f ErrorHandler) {
+	if f == nil {
+		f = defaultErrorHandler
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = f
+}
+
+// SetKeys sets the keys of the built-in fields.
+func (h *handler) SetKeys(k Keys) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.keys = k
+}
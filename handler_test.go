@@ -0,0 +1,146 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlerKeys(t *testing.T) {
+	keys := log.Keys{
+		Time:    "ts",
+		Level:   "lvl",
+		Message: "message",
+		Caller:  "",
+		Prefix:  "component",
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.JSONFormatter),
+			log.UseReportTimestamp(true),
+			log.UseReportCaller(true),
+			log.UsePrefix("db"),
+			log.UseKeys(keys),
+		)
+		logger.Info("test message", "key", "value")
+
+		var m map[string]any
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+		assert.Contains(t, m, "ts")
+		assert.Equal(t, "info", m["lvl"])
+		assert.Equal(t, "test message", m["message"])
+		assert.Equal(t, "db", m["component"])
+		assert.Equal(t, "value", m["key"])
+		assert.NotContains(t, m, "time")
+		assert.NotContains(t, m, "level")
+		assert.NotContains(t, m, "msg")
+		assert.NotContains(t, m, "caller")
+		assert.NotContains(t, m, "prefix")
+	})
+
+	t.Run("Logfmt", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.LogfmtFormatter),
+			log.UsePrefix("db"),
+			log.UseKeys(keys),
+		)
+		logger.Info("test message", "key", "value")
+		assert.Equal(t, "lvl=info component=db message=\"test message\" key=value\n", buf.String())
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		k := log.DefaultKeys()
+		k.Level = ""
+		logger := log.New(log.UseOutput(&buf), log.UseKeys(k))
+		logger.Info("test message")
+		assert.Equal(t, "test message\n", buf.String())
+	})
+
+	t.Run("SetKeys", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.LogfmtFormatter))
+		log.SetKeys(keys, logger)
+		logger.Info("test message")
+		assert.Equal(t, "lvl=info message=\"test message\"\n", buf.String())
+	})
+}
+
+func TestHandlerGroups(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseFormatter(log.LogfmtFormatter),
+	)
+	logger.WithGroup("req").With("id", 1).Info("test message",
+		slog.Group("user", slog.String("name", "bob")))
+	assert.Equal(t, "level=info msg=\"test message\" req.id=1 req.user.name=bob\n", buf.String())
+}
+
+func TestHandlerText(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(log.UseOutput(&buf), log.UsePrefix("TEST"))
+	logger.With("foo", "bar").Info("test message", "foobar", "baz qux")
+	assert.Equal(t, "INFO TEST: test message foo=bar foobar=\"baz qux\"\n", buf.String())
+}
+
+func TestHandlerReplaceAttr(t *testing.T) {
+	var groups [][]string
+	replace := func(g []string, a slog.Attr) slog.Attr {
+		groups = append(groups, g)
+		switch {
+		case a.Key == "level":
+			return slog.String("severity", "INFO")
+		case a.Key == "secret":
+			return slog.Attr{}
+		case a.Value.Kind() == slog.KindDuration:
+			return slog.Float64(a.Key, a.Value.Duration().Seconds())
+		}
+		return a
+	}
+
+	t.Run("Logfmt", func(t *testing.T) {
+		groups = nil
+		var buf bytes.Buffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.LogfmtFormatter),
+			log.UseReplaceAttr(replace),
+		)
+		logger.WithGroup("req").Info("test message",
+			"secret", "hunter2",
+			"took", 1500*time.Millisecond,
+		)
+		assert.Equal(t, "severity=INFO msg=\"test message\" req.took=1.5\n", buf.String())
+		assert.Equal(t, [][]string{nil, nil, {"req"}, {"req"}}, groups)
+	})
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReplaceAttr(replace))
+		logger.With("secret", "hunter2").Info("test message")
+		assert.Equal(t, "INFO test message\n", buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(
+			log.UseOutput(&buf),
+			log.UseFormatter(log.JSONFormatter),
+			log.UseReplaceAttr(replace),
+		)
+		logger.Info("test message", "took", time.Second)
+		assert.JSONEq(t, `{"severity":"INFO","msg":"test message","took":1}`, buf.String())
+	})
+}
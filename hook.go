@@ -0,0 +1,179 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+)
+
+// AllLevels are all the levels, from [DebugLevel] to [FatalLevel].
+var AllLevels = []Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel}
+
+// Hook is run for each record before it is written.
+//
+// The record passed to Fire has the attributes of the logger followed by
+// its own, with group-qualified keys, as they are written. A hook fires for
+// the levels returned by Levels and for the intermediate levels above each
+// of them, such as slog.LevelWarn+2 for [WarnLevel]. Records logged with
+// [Print] only fire the hooks for all of [AllLevels].
+//
+// Fire is called without holding the logger's lock, so a hook may log.
+// Errors returned by Fire are passed to the logger's error handler. To
+// change the record that is written, implement [MutatingHook].
+type Hook interface {
+	// Levels returns the levels the hook fires for.
+	Levels() []Level
+	// Fire runs the hook for the record.
+	Fire(ctx context.Context, r slog.Record) error
+}
+
+// MutatingHook is a [Hook] that can change the record that is written, for
+// example to add, remove or redact attributes.
+type MutatingHook interface {
+	Hook
+	// Mutate is called instead of Fire, and returns the record to write
+	// instead of r. If it returns an error, r is written unchanged.
+	Mutate(ctx context.Context, r slog.Record) (slog.Record, error)
+}
+
+// ErrorHandler handles errors that cannot be returned to the caller of a
+// logging function.
+type ErrorHandler = func(error)
+
+// defaultErrorHandler writes the error to [os.Stderr].
+func defaultErrorHandler(err error) {
+	fmt.Fprintf(os.Stderr, "log: %v\n", err)
+}
+
+// hookFunc is a [Hook] implemented by a function.
+type hookFunc struct {
+	f      func(context.Context, slog.Record) error
+	levels []Level
+}
+
+// NewHook returns a hook that calls f for records with the given levels.
+// If no level is given, the hook fires for [AllLevels].
+func NewHook(f func(ctx context.Context, r slog.Record) error, levels ...Level) Hook {
+	if len(levels) == 0 {
+		levels = AllLevels
+	}
+	return &hookFunc{f: f, levels: levels}
+}
+
+func (h *hookFunc) Levels() []Level { return h.levels }
+
+func (h *hookFunc) Fire(ctx context.Context, r slog.Record) error { return h.f(ctx, r) }
+
+// mutatingHookFunc is a [MutatingHook] implemented by a function.
+type mutatingHookFunc struct {
+	f      func(context.Context, slog.Record) (slog.Record, error)
+	levels []Level
+}
+
+// NewMutatingHook returns a hook that replaces the records with the given
+// levels with the result of f. If no level is given, the hook fires for
+// [AllLevels].
+func NewMutatingHook(f func(ctx context.Context, r slog.Record) (slog.Record, error), levels ...Level) MutatingHook {
+	if len(levels) == 0 {
+		levels = AllLevels
+	}
+	return &mutatingHookFunc{f: f, levels: levels}
+}
+
+func (h *mutatingHookFunc) Levels() []Level { return h.levels }
+
+func (h *mutatingHookFunc) Fire(ctx context.Context, r slog.Record) error {
+	_, err := h.f(ctx, r)
+	return err
+}
+
+func (h *mutatingHookFunc) Mutate(ctx context.Context, r slog.Record) (slog.Record, error) {
+	return h.f(ctx, r)
+}
+
+// hookFires reports whether a hook with the given levels fires for records
+// at the level.
+func hookFires(levels []Level, level Level) bool {
+	if level == noLevel {
+		return !slices.ContainsFunc(AllLevels, func(l Level) bool { return !slices.Contains(levels, l) })
+	}
+	if slices.Contains(levels, level) {
+		return true
+	}
+	base, _ := baseLevel(level)
+	return base <= level && slices.Contains(levels, base)
+}
+
+// fireHooks fires the hooks registered for the record level. It returns
+// the record to write, and whether its attributes are already qualified
+// and include the logger attributes, which is the case if a hook fired.
+func (h *handler) fireHooks(ctx context.Context, r slog.Record) (slog.Record, bool) {
+	h.mu.RLock()
+	hooks, onError := h.hooks, h.onError
+	h.mu.RUnlock()
+
+	qualified := false
+	for _, hook := range hooks {
+		if !hookFires(hook.Levels(), Level(r.Level)) {
+			continue
+		}
+		if !qualified {
+			r, qualified = h.hookRecord(r), true
+		}
+		if m, ok := hook.(MutatingHook); ok {
+			mr, err := m.Mutate(ctx, r)
+			if err != nil {
+				onError(fmt.Errorf("hook: %w", err))
+				continue
+			}
+			r = mr
+			continue
+		}
+		if err := hook.Fire(ctx, r); err != nil {
+			onError(fmt.Errorf("hook: %w", err))
+		}
+	}
+	return r, qualified
+}
+
+// hookRecord returns a copy of the record with the logger attributes and
+// its own, with group-qualified keys.
+func (h *handler) hookRecord(r slog.Record) slog.Record {
+	h.mu.RLock()
+	attrs := slices.Clone(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = h.appendAttrs(attrs, h.groups, a)
+		return true
+	})
+	h.mu.RUnlock()
+
+	hr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	hr.AddAttrs(attrs...)
+	return hr
+}
+
+// flattenAttrs appends the attributes to dst, resolving their values and
+// flattening groups into dot-separated keys, without replacing them.
+func flattenAttrs(dst []slog.Attr, groups []string, attrs ...slog.Attr) []slog.Attr {
+	for _, a := range attrs {
+		a.Value = a.Value.Resolve()
+		switch {
+		case a.Equal(slog.Attr{}):
+		case a.Value.Kind() == slog.KindGroup:
+			g := groups
+			if a.Key != "" {
+				g = append(groups[:len(groups):len(groups)], a.Key)
+			}
+			dst = flattenAttrs(dst, g, a.Value.Group()...)
+		case len(groups) > 0:
+			a.Key = strings.Join(groups, ".") + "." + a.Key
+			dst = append(dst, a)
+		default:
+			dst = append(dst, a)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,125 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		fired []string
+		errs  []error
+	)
+	alert := log.NewHook(func(_ context.Context, r slog.Record) error {
+		attrs := map[string]string{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value.String()
+			return true
+		})
+		fired = append(fired, r.Message+" "+attrs["svc"]+" "+attrs["key"])
+		return errors.New("webhook unavailable")
+	}, log.ErrorLevel)
+
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseHooks(alert),
+		log.UseErrorHandler(func(err error) { errs = append(errs, err) }),
+	).With("svc", "api")
+
+	logger.Info("info message")
+	logger.Error("error message", "key", "value")
+	assert.Equal(t, []string{"error message api value"}, fired)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "hook: webhook unavailable")
+	assert.Contains(t, buf.String(), "error message")
+}
+
+func TestAddHook(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		count int
+	)
+	logger := log.New(log.UseOutput(&buf))
+	log.AddHook(log.NewHook(func(ctx context.Context, r slog.Record) error {
+		count++
+		// Hooks may log without deadlocking.
+		logger.Debug("hook fired")
+		return nil
+	}), logger)
+	log.SetErrorHandler(nil, logger)
+
+	logger.Info("test message")
+	logger.Warn("test message")
+	assert.Equal(t, 2, count)
+}
+
+func TestHookLevels(t *testing.T) {
+	var fired []slog.Level
+	record := func(_ context.Context, r slog.Record) error {
+		fired = append(fired, r.Level)
+		return nil
+	}
+	logger := log.New(
+		log.UseOutput(&bytes.Buffer{}),
+		log.UseLevel(log.FromSlogLevel(slog.Level(-8))),
+		log.UseHooks(log.NewHook(record, log.WarnLevel)),
+	)
+	ctx := context.Background()
+	for _, l := range []slog.Level{slog.Level(-8), slog.LevelInfo, slog.LevelWarn, slog.LevelWarn + 2, slog.LevelError} {
+		logger.Log(ctx, l, "test message")
+	}
+	assert.Equal(t, []slog.Level{slog.LevelWarn, slog.LevelWarn + 2}, fired)
+
+	t.Run("Print", func(t *testing.T) {
+		var all, warn int
+		logger := log.New(log.UseOutput(&bytes.Buffer{}), log.UseHooks(
+			log.NewHook(func(context.Context, slog.Record) error { all++; return nil }),
+			log.NewHook(func(context.Context, slog.Record) error { warn++; return nil }, log.WarnLevel, log.ErrorLevel, log.FatalLevel),
+		))
+		// Print logs at the highest level.
+		logger.Log(ctx, slog.Level(math.MaxInt32), "test message")
+		assert.Equal(t, 1, all)
+		assert.Equal(t, 0, warn)
+	})
+}
+
+func TestMutatingHook(t *testing.T) {
+	var buf bytes.Buffer
+	redact := log.NewMutatingHook(func(_ context.Context, r slog.Record) (slog.Record, error) {
+		nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == "req.token" {
+				a.Value = slog.StringValue("REDACTED")
+			}
+			nr.AddAttrs(a)
+			return true
+		})
+		nr.AddAttrs(slog.Group("hook", slog.Bool("redacted", true)))
+		return nr, nil
+	})
+	failing := log.NewMutatingHook(func(_ context.Context, r slog.Record) (slog.Record, error) {
+		return slog.Record{}, errors.New("unavailable")
+	})
+
+	var errs []error
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseFormatter(log.LogfmtFormatter),
+		log.UseHooks(redact, failing),
+		log.UseErrorHandler(func(err error) { errs = append(errs, err) }),
+	).With("svc", "api").WithGroup("req")
+	logger.Info("test message", "token", "secret", "id", 1)
+
+	assert.Equal(t, "level=info msg=\"test message\" svc=api req.token=REDACTED req.id=1 hook.redacted=true\n", buf.String())
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], "hook: unavailable")
+}
@@ -0,0 +1,231 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// The JSON formatter writes the same bytes as encoding a map of the entry
+// with [encoding/json] without HTML escaping: keys are sorted, later keys
+// replace earlier ones, and errors and [fmt.Stringer]s are encoded as
+// strings. Common value kinds are encoded without reflection.
+
+var jsonAttrsPool = sync.Pool{
+	New: func() interface{} {
+		s := make([]slog.Attr, 0, 16)
+		return &s
+	},
+}
+
+// errUnsupportedFloat is returned for floats that JSON cannot represent.
+var errUnsupportedFloat = errors.New("log: unsupported float value")
+
+// jsonFormatter writes the entry as a JSON object. It must be called with
+// the lock held. If a value cannot be encoded, nothing is written.
+func (h *handler) jsonFormatter(b *bytes.Buffer, e entry) {
+	p := jsonAttrsPool.Get().(*[]slog.Attr)
+	attrs := (*p)[:0]
+	defer func() {
+		clear(attrs)
+		*p = attrs[:0]
+		jsonAttrsPool.Put(p)
+	}()
+
+	if e.time.Key != "" {
+		attrs = append(attrs, slog.String(e.time.Key, h.formatTime(e.time.Value)))
+	}
+	if e.lvl.Key != "" {
+		if level, ok := e.lvl.Value.Any().(Level); ok {
+			if field, ok := h.levelField(level); ok {
+				attrs = append(attrs, slog.String(e.lvl.Key, field))
+			}
+		} else {
+			attrs = append(attrs, e.lvl)
+		}
+	}
+	if h.callerFields && e.caller.Key != "" && e.frame.File != "" {
+		e.caller.Value = slog.AnyValue(callerFields{e.frame.File, e.frame.Line, e.frame.Function})
+	}
+	for _, a := range [...]slog.Attr{e.caller, e.prefix, e.message} {
+		if a.Key != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	for _, a := range e.attrs {
+		if a.Key != "" {
+			attrs = append(attrs, a)
+		}
+	}
+	slices.SortStableFunc(attrs, func(a, b slog.Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	start := b.Len()
+	b.WriteByte('{')
+	first := true
+	for i, a := range attrs {
+		if i+1 < len(attrs) && attrs[i+1].Key == a.Key {
+			continue
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		writeJSONString(b, a.Key)
+		b.WriteByte(':')
+		if err := writeJSONValue(b, a.Value); err != nil {
+			b.Truncate(start)
+			return
+		}
+	}
+	b.WriteString("}\n")
+}
+
+// writeJSONValue writes the JSON encoding of the value. Errors and
+// [fmt.Stringer]s are encoded as strings.
+func writeJSONValue(b *bytes.Buffer, v slog.Value) error {
+	switch v.Kind() {
+	case slog.KindString:
+		writeJSONString(b, v.String())
+	case slog.KindInt64:
+		b.Write(strconv.AppendInt(b.AvailableBuffer(), v.Int64(), 10))
+	case slog.KindUint64:
+		b.Write(strconv.AppendUint(b.AvailableBuffer(), v.Uint64(), 10))
+	case slog.KindFloat64:
+		return writeJSONFloat(b, v.Float64())
+	case slog.KindBool:
+		b.Write(strconv.AppendBool(b.AvailableBuffer(), v.Bool()))
+	case slog.KindDuration:
+		writeJSONString(b, v.Duration().String())
+	case slog.KindTime:
+		writeJSONString(b, v.Time().String())
+	default:
+		switch x := v.Any().(type) {
+		case callerFields:
+			x.writeJSON(b)
+		case error:
+			writeJSONString(b, x.Error())
+		case fmt.Stringer:
+			writeJSONString(b, x.String())
+		default:
+			return writeJSONAny(b, x)
+		}
+	}
+	return nil
+}
+
+// writeJSONAny writes the JSON encoding of x using [encoding/json].
+func writeJSONAny(b *bytes.Buffer, x any) error {
+	start := b.Len()
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(x); err != nil {
+		return err
+	}
+	// Remove the newline added by the encoder.
+	if b.Len() > start {
+		b.Truncate(b.Len() - 1)
+	}
+	return nil
+}
+
+// writeJSONFloat writes the float formatted like [encoding/json] does.
+func writeJSONFloat(b *bytes.Buffer, f float64) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return errUnsupportedFloat
+	}
+	// Use exponent notation for very small and very large numbers, like
+	// ES6 and encoding/json.
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	buf := strconv.AppendFloat(b.AvailableBuffer(), f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9.
+		if n := len(buf); n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+	b.Write(buf)
+	return nil
+}
+
+// writeJSONString writes s as a quoted JSON string, escaping it like
+// [encoding/json] does with HTML escaping disabled.
+func writeJSONString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b.WriteString(s[start:i])
+			switch c {
+			case '"', '\\':
+				b.WriteByte('\\')
+				b.WriteByte(c)
+			case '\b':
+				b.WriteString(`\b`)
+			case '\f':
+				b.WriteString(`\f`)
+			case '\n':
+				b.WriteString(`\n`)
+			case '\r':
+				b.WriteString(`\r`)
+			case '\t':
+				b.WriteString(`\t`)
+			default:
+				b.WriteString(`\u00`)
+				b.WriteByte(lowerhex[c>>4])
+				b.WriteByte(lowerhex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			b.WriteString(s[start:i])
+			b.WriteString("\ufffd")
+		case r == '\u2028' || r == '\u2029':
+			// U+2028 and U+2029 are escaped for JSONP compatibility.
+			b.WriteString(s[start:i])
+			b.WriteString(`\u202`)
+			b.WriteByte(lowerhex[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+	b.WriteString(s[start:])
+	b.WriteByte('"')
+}
+
+// jsonValue returns the value to encode for the given attribute value with
+// [encoding/json]. Errors and [fmt.Stringer]s are encoded as strings.
+func jsonValue(v slog.Value) interface{} {
+	switch v := v.Any().(type) {
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,204 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// jsonMapFormatter is the reference JSON formatter, which encodes a map of
+// the entry with encoding/json.
+func (h *handler) jsonMapFormatter(b *bytes.Buffer, e entry) {
+	m := make(map[string]interface{}, len(e.attrs)+5)
+	if e.time.Key != "" {
+		m[e.time.Key] = h.formatTime(e.time.Value)
+	}
+	if e.lvl.Key != "" {
+		if level, ok := e.lvl.Value.Any().(Level); ok {
+			m[e.lvl.Key] = level.String()
+		} else {
+			m[e.lvl.Key] = jsonValue(e.lvl.Value)
+		}
+	}
+	for _, a := range []slog.Attr{e.caller, e.prefix, e.message} {
+		if a.Key != "" {
+			m[a.Key] = jsonValue(a.Value)
+		}
+	}
+	for _, a := range e.attrs {
+		if a.Key != "" {
+			m[a.Key] = jsonValue(a.Value)
+		}
+	}
+
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(m)
+}
+
+type jsonPoint struct {
+	X, Y int
+}
+
+type jsonMarshaler struct{}
+
+func (jsonMarshaler) MarshalJSON() ([]byte, error) { return []byte(`{ "a" : [1, 2] }`), nil }
+
+// assertJSONEqual asserts that both JSON formatters write the same bytes.
+func assertJSONEqual(t *testing.T, h *handler, r slog.Record) {
+	t.Helper()
+	e := h.entry(r, false)
+	var got, want bytes.Buffer
+	h.jsonFormatter(&got, e)
+	h.jsonMapFormatter(&want, e)
+	assert.Equal(t, want.String(), got.String())
+}
+
+func TestJSONFormatterDifferential(t *testing.T) {
+	h := newHandler(&Options{
+		LogOptions: &LogOptions{
+			Level:           DebugLevel,
+			Prefix:          "prefix",
+			ReportTimestamp: true,
+			ReportCaller:    true,
+			Formatter:       JSONFormatter,
+		},
+		Keys: DefaultKeys(),
+	})
+	now := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	values := map[string]slog.Value{
+		"string":     slog.StringValue("hello world"),
+		"empty":      slog.StringValue(""),
+		"escape":     slog.StringValue("a\"b\\c\nd\re\tf\bg\fh\x00i\x1fj\x7fk"),
+		"html":       slog.StringValue("<a href=\"x\">&</a>"),
+		"unicode":    slog.StringValue("héllo, 世界 \u2028\u2029 🚀"),
+		"invalid":    slog.StringValue("a\xffb\xc3"),
+		"int":        slog.IntValue(-42),
+		"int64":      slog.Int64Value(math.MinInt64),
+		"uint64":     slog.Uint64Value(math.MaxUint64),
+		"float":      slog.Float64Value(3.14),
+		"float0":     slog.Float64Value(0),
+		"floatneg0":  slog.Float64Value(math.Copysign(0, -1)),
+		"floatsmall": slog.Float64Value(1e-7),
+		"floatbig":   slog.Float64Value(1e21),
+		"floatexp":   slog.Float64Value(-1.5e-300),
+		"floatint":   slog.Float64Value(100),
+		"bool":       slog.BoolValue(true),
+		"duration":   slog.DurationValue(1500 * time.Millisecond),
+		"time":       slog.TimeValue(now),
+		"error":      slog.AnyValue(errors.New("boom")),
+		"stringer":   slog.AnyValue(net.IPv4(127, 0, 0, 1)),
+		"struct":     slog.AnyValue(jsonPoint{1, 2}),
+		"map":        slog.AnyValue(map[string]any{"b": 1, "a": "<x>"}),
+		"slice":      slog.AnyValue([]string{"a", "b"}),
+		"bytes":      slog.AnyValue([]byte("bytes")),
+		"nil":        slog.AnyValue(nil),
+		"marshaler":  slog.AnyValue(jsonMarshaler{}),
+		"level":      slog.AnyValue(WarnLevel),
+	}
+
+	for name, v := range values {
+		t.Run(name, func(t *testing.T) {
+			r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+			r.AddAttrs(slog.Attr{Key: name, Value: v})
+			assertJSONEqual(t, h, r)
+		})
+	}
+
+	t.Run("AllValues", func(t *testing.T) {
+		r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+		for name, v := range values {
+			r.AddAttrs(slog.Attr{Key: name, Value: v})
+		}
+		assertJSONEqual(t, h, r)
+	})
+
+	t.Run("DuplicateKeys", func(t *testing.T) {
+		r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+		r.AddAttrs(slog.String("msg", "first"), slog.Int("a", 1), slog.Int("a", 2), slog.String("level", "x"))
+		assertJSONEqual(t, h, r)
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		h := h.WithGroup("g").WithAttrs([]slog.Attr{slog.Int("a", 1)}).(*handler)
+		r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+		r.AddAttrs(slog.Group("sub", slog.String("b", "c")))
+		assertJSONEqual(t, h, r)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		for _, v := range []slog.Value{
+			slog.Float64Value(math.NaN()),
+			slog.Float64Value(math.Inf(1)),
+			slog.AnyValue(make(chan int)),
+		} {
+			r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+			r.AddAttrs(slog.Any("bad", v))
+			assertJSONEqual(t, h, r)
+		}
+	})
+
+	t.Run("ReplaceAttr", func(t *testing.T) {
+		h := newHandler(&Options{
+			LogOptions: &LogOptions{Level: DebugLevel, Formatter: JSONFormatter},
+			Keys:       DefaultKeys(),
+			ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+				if a.Key == "level" {
+					return slog.Int("severity", 9)
+				}
+				return a
+			},
+		})
+		r := slog.NewRecord(now, slog.LevelInfo, "message", 0)
+		assertJSONEqual(t, h, r)
+	})
+
+	t.Run("RandomStrings", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(1))
+		alphabet := []rune{'a', 'Z', ' ', '"', '\\', '\n', '\x01', '\x7f', '<', 'é', '世', '\u2028', '\u2029', '\ufffd', '🚀'}
+		for range 500 {
+			var s []byte
+			for range rnd.Intn(16) {
+				if rnd.Intn(8) == 0 {
+					s = append(s, byte(rnd.Intn(256)))
+				} else {
+					s = append(s, string(alphabet[rnd.Intn(len(alphabet))])...)
+				}
+			}
+			r := slog.NewRecord(now, slog.LevelInfo, string(s), 0)
+			r.AddAttrs(slog.String(string(s), string(s)))
+			assertJSONEqual(t, h, r)
+		}
+	})
+}
+
+func TestJSONFormatterAllocs(t *testing.T) {
+	h := newHandler(&Options{
+		LogOptions: &LogOptions{Level: DebugLevel, Formatter: JSONFormatter},
+		Keys:       DefaultKeys(),
+	})
+	r := slog.NewRecord(time.Now(), slog.LevelInfo, "message", 0)
+	r.AddAttrs(
+		slog.String("key", "value \"quoted\""),
+		slog.Int("n", 4242),
+		slog.Float64("f", 1.5),
+		slog.Duration("elapsed", time.Second),
+	)
+	e := h.entry(r, false)
+	var b bytes.Buffer
+	h.jsonFormatter(&b, e) // Warm up the pool and the buffer.
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Reset()
+		h.jsonFormatter(&b, e)
+	})
+	assert.Zero(t, allocs)
+}
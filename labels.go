@@ -0,0 +1,165 @@
+package log
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LevelLabels customize the level labels of the text formatter.
+type LevelLabels struct {
+	// Labels are the labels by level. A label replaces the text of the
+	// level style and is never truncated. Levels without a label keep the
+	// text of their style.
+	Labels map[Level]string
+
+	// Width is the width to which labels are padded with spaces, so that
+	// messages are aligned. Default is 0, which disables padding.
+	Width int
+
+	// Structured is whether the labels are also used for the level field of
+	// the JSON and logfmt formatters, instead of the level names. Default is
+	// false.
+	Structured bool
+}
+
+// FullLevelLabels returns labels with the full upper-case level names,
+// padded to the same width.
+func FullLevelLabels() LevelLabels {
+	return newLevelLabels(map[Level]string{
+		DebugLevel: "DEBUG",
+		InfoLevel:  "INFO",
+		WarnLevel:  "WARN",
+		ErrorLevel: "ERROR",
+		FatalLevel: "FATAL",
+	})
+}
+
+// LetterLevelLabels returns single-letter labels.
+func LetterLevelLabels() LevelLabels {
+	return newLevelLabels(map[Level]string{
+		DebugLevel: "D",
+		InfoLevel:  "I",
+		WarnLevel:  "W",
+		ErrorLevel: "E",
+		FatalLevel: "F",
+	})
+}
+
+// IconLevelLabels returns emoji labels, padded to the same width.
+func IconLevelLabels() LevelLabels {
+	return newLevelLabels(map[Level]string{
+		DebugLevel: "🐛",
+		InfoLevel:  "💡",
+		WarnLevel:  "🔶",
+		ErrorLevel: "🔴",
+		FatalLevel: "💀",
+	})
+}
+
+// newLevelLabels returns labels padded to the width of the widest label.
+func newLevelLabels(labels map[Level]string) LevelLabels {
+	l := LevelLabels{Labels: labels}
+	for _, label := range labels {
+		l.Width = max(l.Width, lipgloss.Width(label))
+	}
+	return l
+}
+
+// clone returns a copy of the labels.
+func (l *LevelLabels) clone() *LevelLabels {
+	if l == nil {
+		return nil
+	}
+	c := *l
+	c.Labels = maps.Clone(l.Labels)
+	return &c
+}
+
+// levelStyle returns the style and the label of the level, if any. An
+// intermediate level without a style or label of its own gets those of
+// its named level, with the label suffixed by its offset, such as
+// "WARN+2". Records logged with [Print] have no level. It must be called
+// with the lock held.
+func (h *handler) levelStyle(level Level) (s lipgloss.Style, label string, ok bool) {
+	if level == noLevel {
+		return s, "", false
+	}
+	s, label, ok = h.lookupLevel(level)
+	if ok {
+		return s, label, true
+	}
+	base, offset := baseLevel(level)
+	if offset == 0 {
+		return s, "", false
+	}
+	s, label, ok = h.lookupLevel(base)
+	if !ok {
+		return s, "", false
+	}
+	if label == "" {
+		label = s.Value()
+	}
+	return s, label + levelOffset(offset), true
+}
+
+// lookupLevel returns the style and the custom label of the level. It must
+// be called with the lock held.
+func (h *handler) lookupLevel(level Level) (lipgloss.Style, string, bool) {
+	s, ok := h.styles.Levels[level]
+	if h.levelLabels != nil {
+		if label, found := h.levelLabels.Labels[level]; found {
+			return s, label, true
+		}
+	}
+	return s, "", ok
+}
+
+// hasLevel reports whether the level has a style or a label. It must be
+// called with the lock held.
+func (h *handler) hasLevel(level Level) bool {
+	_, _, ok := h.levelStyle(level)
+	return ok
+}
+
+// levelText returns the styled and padded level label, or an empty string
+// if the level has neither a style nor a label. It must be called with the
+// lock held.
+func (h *handler) levelText(level Level) string {
+	s, label, ok := h.levelStyle(level)
+	if !ok {
+		return ""
+	}
+	if label != "" {
+		s = s.SetString(label).UnsetMaxWidth()
+	}
+	str := s.Renderer(h.re).String()
+	if h.levelLabels == nil {
+		return str
+	}
+	if pad := h.levelLabels.Width - lipgloss.Width(str); pad > 0 {
+		str += strings.Repeat(" ", pad)
+	}
+	return str
+}
+
+// levelField returns the value of the level field of the JSON and logfmt
+// formatters, and false for records without a level, logged with [Print].
+// It must be called with the lock held.
+func (h *handler) levelField(level Level) (string, bool) {
+	if level == noLevel {
+		return "", false
+	}
+	if h.levelLabels != nil && h.levelLabels.Structured {
+		if label, ok := h.levelLabels.Labels[level]; ok {
+			return label, true
+		}
+		if base, offset := baseLevel(level); offset != 0 {
+			if label, ok := h.levelLabels.Labels[base]; ok {
+				return label + levelOffset(offset), true
+			}
+		}
+	}
+	return levelName(level), true
+}
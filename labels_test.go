@@ -0,0 +1,57 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels log.LevelLabels
+		want   string
+	}{
+		{"Full", log.FullLevelLabels(), "DEBUG one\nINFO  two\nWARN  three\n"},
+		{"Letter", log.LetterLevelLabels(), "D one\nI two\nW three\n"},
+		{"Icon", log.IconLevelLabels(), "🐛 one\n💡 two\n🔶 three\n"},
+		{
+			"Custom",
+			log.LevelLabels{Labels: map[log.Level]string{log.WarnLevel: "WARNING"}, Width: 7},
+			"DEBUG   one\nINFO    two\nWARNING three\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := log.New(log.UseOutput(&buf), log.UseLevel(log.DebugLevel), log.UseLevelLabels(tt.labels))
+			logger.Debug("one")
+			logger.Info("two")
+			logger.Warn("three")
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+
+	t.Run("Structured", func(t *testing.T) {
+		labels := log.LetterLevelLabels()
+		labels.Structured = true
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.JSONFormatter), log.UseLevelLabels(labels))
+		logger.Info("message")
+		assert.Equal(t, `{"level":"I","msg":"message"}`+"\n", buf.String())
+
+		buf.Reset()
+		log.SetFormatter(log.LogfmtFormatter, logger)
+		logger.Info("message")
+		assert.Equal(t, "level=I msg=message\n", buf.String())
+	})
+
+	t.Run("Unstructured", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.JSONFormatter), log.UseLevelLabels(log.LetterLevelLabels()))
+		logger.Info("message")
+		assert.Equal(t, `{"level":"info","msg":"message"}`+"\n", buf.String())
+	})
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/charmbracelet/log"
+)
+
+// Levels have the same numeric values as [slog.Level]s, so conversions
+// between them are lossless. Levels between the named levels, such as
+// slog.LevelWarn+2, are named after the named level below them, such as
+// "warn+2", and are formatted with its style.
+
+// ErrInvalidLevel is returned by [ParseLevel] for invalid level names.
+var ErrInvalidLevel = log.ErrInvalidLevel
+
+// SlogLevel returns the [slog.Level] of the level.
+func SlogLevel(l Level) slog.Level {
+	return slog.Level(l)
+}
+
+// FromSlogLevel returns the level of the [slog.Level].
+func FromSlogLevel(l slog.Level) Level {
+	return Level(l)
+}
+
+// ParseLevel converts a level name to a [Level]. Besides the named levels,
+// it accepts intermediate levels, such as "warn+2" or "debug-4".
+func ParseLevel(s string) (Level, error) {
+	level, err := log.ParseLevel(s)
+	if err == nil {
+		return level, nil
+	}
+	name, offset, ok := strings.Cut(s, "+")
+	if !ok {
+		name, offset, ok = strings.Cut(s, "-")
+		offset = "-" + offset
+	}
+	if !ok {
+		return 0, err
+	}
+	base, berr := log.ParseLevel(name)
+	n, nerr := strconv.ParseInt(offset, 10, 32)
+	if berr != nil || nerr != nil {
+		return 0, err
+	}
+	return base + Level(n), nil
+}
+
+// namedLevels are the named levels, in increasing order.
+var namedLevels = [...]Level{DebugLevel, InfoLevel, WarnLevel, ErrorLevel, FatalLevel}
+
+// baseLevel returns the named level below or at the level, or the debug
+// level for lower levels, and the offset of the level from it.
+func baseLevel(l Level) (Level, int) {
+	base := DebugLevel
+	for _, named := range namedLevels {
+		if named <= l {
+			base = named
+		}
+	}
+	return base, int(l) - int(base)
+}
+
+// levelName returns the lower-case name of the level, such as "warn" or
+// "warn+2".
+func levelName(l Level) string {
+	if l == noLevel {
+		return "none"
+	}
+	base, offset := baseLevel(l)
+	return base.String() + levelOffset(offset)
+}
+
+// levelOffset returns the offset suffix of an intermediate level name.
+func levelOffset(offset int) string {
+	switch {
+	case offset > 0:
+		return "+" + strconv.Itoa(offset)
+	case offset < 0:
+		return strconv.Itoa(offset)
+	default:
+		return ""
+	}
+}
+
+// levelerRef holds the [slog.Leveler] of a handler.
+type levelerRef struct {
+	slog.Leveler
+}
+
+// minLevel returns the minimum level of the records written to the output.
+func (h *handler) minLevel() Level {
+	if l := h.leveler.Load(); l != nil {
+		return Level(l.Level())
+	}
+	return Level(atomic.LoadInt32(&h.level))
+}
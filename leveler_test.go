@@ -0,0 +1,89 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeveler(t *testing.T) {
+	var lv slog.LevelVar
+	var buf1, buf2, buf3 bytes.Buffer
+	logger1 := log.New(log.UseOutput(&buf1), log.UseLeveler(&lv))
+	logger2 := log.New(log.UseOutput(&buf2), log.UseLeveler(&lv))
+	third := slog.New(slog.NewTextHandler(&buf3, &slog.HandlerOptions{Level: &lv}))
+
+	for _, l := range []*slog.Logger{logger1, logger2, third} {
+		l.Debug("hidden")
+	}
+	lv.Set(slog.LevelDebug)
+	for _, l := range []*slog.Logger{logger1, logger2, third} {
+		l.Debug("shown")
+	}
+	assert.Equal(t, "DEBUG shown\n", buf1.String())
+	assert.Equal(t, "DEBUG shown\n", buf2.String())
+	assert.Contains(t, buf3.String(), "msg=shown")
+	assert.NotContains(t, buf3.String(), "hidden")
+	assert.True(t, logger1.With("key", "value").Enabled(context.Background(), slog.LevelDebug))
+
+	t.Run("SetLevel", func(t *testing.T) {
+		buf1.Reset()
+		log.SetLevel(log.WarnLevel, logger1)
+		logger1.Info("hidden")
+		logger2.Info("shown")
+		assert.Empty(t, buf1.String())
+		assert.Equal(t, slog.LevelDebug, lv.Level())
+
+		log.SetLeveler(&lv, logger1)
+		logger1.Info("shown")
+		assert.Equal(t, "INFO shown\n", buf1.String())
+	})
+}
+
+func TestSlogLevels(t *testing.T) {
+	levels := []slog.Level{slog.Level(-8), slog.LevelDebug, slog.LevelInfo + 1, slog.LevelWarn + 2, slog.LevelError, slog.Level(13)}
+	for _, l := range levels {
+		assert.Equal(t, l, log.SlogLevel(log.FromSlogLevel(l)))
+	}
+
+	var buf bytes.Buffer
+	logger := log.New(log.UseOutput(&buf), log.UseLevel(log.FromSlogLevel(slog.Level(-8))))
+	for _, l := range levels {
+		logger.Log(context.Background(), l, "message")
+	}
+	assert.Equal(t, ""+
+		"DEBUG-4 message\n"+
+		"DEBUG message\n"+
+		"INFO+1 message\n"+
+		"WARN+2 message\n"+
+		"ERROR message\n"+
+		"FATAL+1 message\n", buf.String())
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.JSONFormatter))
+		logger.Log(context.Background(), slog.LevelWarn+2, "message")
+		assert.Equal(t, `{"level":"warn+2","msg":"message"}`+"\n", buf.String())
+	})
+
+	t.Run("ParseLevel", func(t *testing.T) {
+		for s, want := range map[string]log.Level{
+			"warn":    log.WarnLevel,
+			"warn+2":  log.FromSlogLevel(slog.LevelWarn + 2),
+			"DEBUG-4": log.FromSlogLevel(slog.Level(-8)),
+		} {
+			got, err := log.ParseLevel(s)
+			require.NoError(t, err, s)
+			assert.Equal(t, want, got, s)
+		}
+		for _, s := range []string{"", "verbose", "warn+x", "+2"} {
+			_, err := log.ParseLevel(s)
+			assert.ErrorIs(t, err, log.ErrInvalidLevel, s)
+		}
+	})
+}
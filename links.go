@@ -0,0 +1,41 @@
+package log
+
+import (
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// Caller link templates, see [Options.CallerLink].
+const (
+	FileCallerLink   = "file://{path}"
+	VSCodeCallerLink = "vscode://file{path}:{line}"
+)
+
+// callerURL returns the URL of the caller location for the template. The
+// {path} placeholder is replaced with the escaped absolute path of the
+// file, and {line} with the line number.
+func callerURL(template string, f runtime.Frame) string {
+	path := filepath.ToSlash(f.File)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return strings.NewReplacer(
+		"{path}", (&url.URL{Path: path}).EscapedPath(),
+		"{line}", strconv.Itoa(f.Line),
+	).Replace(template)
+}
+
+// linkCaller wraps the rendered caller in an OSC 8 hyperlink to the caller
+// location, if caller links are enabled and supported by the output. It
+// must be called with the lock held.
+func (h *handler) linkCaller(s string, e entry) string {
+	if h.callerLink == "" || !h.hyperlinks || e.frame.File == "" || s == "" {
+		return s
+	}
+	return ansi.SetHyperlink(callerURL(h.callerLink, e.frame)) + s + ansi.ResetHyperlink()
+}
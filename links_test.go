@@ -0,0 +1,64 @@
+package log_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCallerLink(t *testing.T) {
+	_, file, _, _ := runtime.Caller(0)
+	path := filepath.ToSlash(file)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows
+	}
+	caller := log.UseCallerFormatter(func(string, int, string) string { return "caller.go:1" })
+
+	t.Run("File", func(t *testing.T) {
+		t.Setenv("FORCE_HYPERLINK", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), caller, log.UseCallerLink(log.FileCallerLink))
+		logger.Info("message")
+		assert.Equal(t, "INFO \x1b]8;;file://"+path+"\x07<caller.go:1>\x1b]8;;\x07 message\n", buf.String())
+	})
+
+	t.Run("VSCode", func(t *testing.T) {
+		t.Setenv("FORCE_HYPERLINK", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), caller, log.UseCallerLink(log.VSCodeCallerLink))
+		_, _, line, _ := runtime.Caller(0)
+		logger.Info("message")
+		assert.Contains(t, buf.String(), "\x1b]8;;vscode://file"+path+":"+strconv.Itoa(line+1)+"\x07")
+	})
+
+	t.Run("Columns", func(t *testing.T) {
+		t.Setenv("FORCE_HYPERLINK", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), caller, log.UseCallerLink(log.FileCallerLink),
+			log.UseColumns(log.Columns{Level: 4, Caller: 8}))
+		logger.Info("message")
+		assert.Equal(t, "INFO \x1b]8;;file://"+path+"\x07<caller…\x1b]8;;\x07 message\n", buf.String())
+	})
+
+	t.Run("NotTerminal", func(t *testing.T) {
+		t.Setenv("FORCE_HYPERLINK", "")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), caller, log.UseCallerLink(log.FileCallerLink))
+		logger.Info("message")
+		assert.Equal(t, "INFO <caller.go:1> message\n", buf.String())
+	})
+
+	t.Run("ForcedOff", func(t *testing.T) {
+		t.Setenv("FORCE_HYPERLINK", "0")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportCaller(true), caller, log.UseCallerLink(log.FileCallerLink))
+		logger.Info("message")
+		assert.NotContains(t, buf.String(), "\x1b]8;")
+	})
+}
@@ -2,10 +2,14 @@
 package log
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -14,45 +18,49 @@ import (
 type Logger = slog.Logger
 
 var (
-	defaultStylesOnce struct {
-		sync.Once
-		s *log.Styles
-	}
-
 	defaultOnce struct {
 		sync.Once
 		l atomic.Pointer[slog.Logger]
 	}
 )
 
-// DefaultStyles returns the default styles.
+// defaultStyles returns the default styles, built once. They are shared
+// by the loggers using them and must not be modified.
+var defaultStyles = sync.OnceValue(func() *Styles {
+	s := log.DefaultStyles()
+
+	// ========= Custom styles =========
+	for _, c := range []struct {
+		Level    Level
+		MaxWidth int // to avoid truncation
+		// ... more custom styles
+	}{
+		{DebugLevel, 5},
+		{InfoLevel, 4},
+		{WarnLevel, 4},
+		{ErrorLevel, 5},
+		{FatalLevel, 5},
+	} {
+		s.Levels[c.Level] = s.Levels[c.Level].MaxWidth(c.MaxWidth)
+	}
+	return s
+})
+
+// DefaultStyles returns a new copy of the default styles.
 // It applies custom styles to the [log.DefaultStyles].
+//
+// Each call returns independent styles, which can be customized without
+// affecting other loggers. See [NewStylesBuilder] to derive styles from the
+// defaults.
 func DefaultStyles() *Styles {
-	defaultStylesOnce.Do(func() {
-		defaultStylesOnce.s = log.DefaultStyles()
-
-		// ========= Custom styles =========
-		for _, s := range []struct {
-			Level    Level
-			MaxWidth int // to avoid truncation
-			// ... more custom styles
-		}{
-			{DebugLevel, 5},
-			{InfoLevel, 4},
-			{WarnLevel, 4},
-			{ErrorLevel, 5},
-			{FatalLevel, 5},
-		} {
-			defaultStylesOnce.s.Levels[s.Level] = defaultStylesOnce.s.Levels[s.Level].
-				MaxWidth(s.MaxWidth)
-		}
-	})
-
-	return defaultStylesOnce.s
+	return CopyStyles(defaultStyles())
 }
 
 // Default returns the default logger.
 func Default() *slog.Logger {
+	if l := defaultOnce.l.Load(); l != nil {
+		return l
+	}
 	defaultOnce.Do(func() {
 		if defaultOnce.l.Load() != nil {
 			return
@@ -66,14 +74,56 @@ func Default() *slog.Logger {
 //  | Helpers 												 	 |
 //  +------------------------------------------------------------+
 
-// handler returns the default logger's handler.
-func handler() *log.Logger {
-	return loggerHandler(Default())
+// loggerHandler returns the logger's handler.
+func loggerHandler(l *slog.Logger) *handler {
+	return l.Handler().(*handler)
 }
 
-// loggerHandler returns the logger's handler.
-func loggerHandler(l *slog.Logger) *log.Logger {
-	return l.Handler().(*log.Logger)
+// logAt logs a message with the default logger at the given level,
+// reporting the caller of the package-level logging function.
+func logAt(level Level, msg string, args ...any) {
+	h := loggerHandler(Default())
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	r := newRecord(level, msg)
+	r.Add(args...)
+	_ = h.Handle(ctx, r)
+}
+
+// logAttrsAt is like logAt, but takes attributes.
+func logAttrsAt(level Level, msg string, attrs ...Attr) {
+	h := loggerHandler(Default())
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	r := newRecord(level, msg)
+	r.AddAttrs(attrs...)
+	_ = h.Handle(ctx, r)
+}
+
+// logfAt is like logAt, but formats the message only if the level is
+// enabled.
+func logfAt(level Level, format string, args ...any) {
+	h := loggerHandler(Default())
+	ctx := context.Background()
+	if !h.Enabled(ctx, slog.Level(level)) {
+		return
+	}
+	r := newRecord(level, fmt.Sprintf(format, args...))
+	_ = h.Handle(ctx, r)
+}
+
+// newRecord returns a record reporting the caller of the package-level
+// logging function.
+func newRecord(level Level, msg string) slog.Record {
+	var pcs [1]uintptr
+	// Skip [runtime.Callers], newRecord, logAt and the logging function.
+	// The caller offset is applied by [handler.Handle].
+	runtime.Callers(4, pcs[:])
+	return slog.NewRecord(time.Now(), slog.Level(level), msg, pcs[0])
 }
 
 // DefaultOptions returns the default options.
@@ -82,8 +132,12 @@ func DefaultOptions() *Options {
 		LogOptions: &LogOptions{
 			Level: log.InfoLevel,
 		},
-		Writer: os.Stderr,
-		Styles: DefaultStyles(),
+		Writer:   os.Stderr,
+		Styles:   DefaultStyles(),
+		Keys:     DefaultKeys(),
+		Sanitize: true,
+
+		LevelOverrides: envLevelOverrides(),
 	}
 }
 
@@ -96,16 +150,9 @@ func New(opts ...Option) *slog.Logger {
 	o := DefaultOptions()
 	o.Apply(opts...)
 
-	handler := log.NewWithOptions(o.Writer, *o.LogOptions)
-
-	if o.Styles != nil {
-		handler.SetStyles(o.Styles)
-	}
-
-	l := slog.New(handler)
+	l := slog.New(newHandler(o))
 
 	if o.Default {
-		log.SetDefault(handler)
 		slog.SetDefault(l)
 		defaultOnce.l.Store(l)
 	}
@@ -117,67 +164,85 @@ func New(opts ...Option) *slog.Logger {
 //  | Logging 												 	 |
 //  +------------------------------------------------------------+
 
+// Enabled reports whether the default logger handles records at the given
+// level.
+func Enabled(level Level) bool {
+	return Default().Enabled(context.Background(), slog.Level(level))
+}
+
 // Debug logs a message with level Debug.
 func Debug(msg string, args ...any) {
-	handler().Debug(msg, args...)
+	logAt(DebugLevel, msg, args...)
 }
 
 // Debugf logs a formatted message with level Debug.
 func Debugf(format string, args ...any) {
-	handler().Debugf(format, args...)
+	logfAt(DebugLevel, format, args...)
 }
 
 // Info logs a message with level Info.
 func Info(msg string, args ...any) {
-	handler().Info(msg, args...)
+	logAt(InfoLevel, msg, args...)
 }
 
 // Infof logs a formatted message with level Info.
 func Infof(format string, args ...any) {
-	handler().Infof(format, args...)
+	logfAt(InfoLevel, format, args...)
 }
 
 // Warn logs a message with level Warn.
 func Warn(msg string, args ...any) {
-	handler().Warn(msg, args...)
+	logAt(WarnLevel, msg, args...)
 }
 
 // Warnf logs a formatted message with level Warn.
 func Warnf(format string, args ...any) {
-	handler().Warnf(format, args...)
+	logfAt(WarnLevel, format, args...)
 }
 
 // Error logs a message with level Error.
 func Error(msg string, args ...any) {
-	handler().Error(msg, args...)
+	logAt(ErrorLevel, msg, args...)
 }
 
 // Errorf logs a formatted message with level Error.
 func Errorf(format string, args ...any) {
-	handler().Errorf(format, args...)
+	logfAt(ErrorLevel, format, args...)
 }
 
 // Fatal logs a message with level Fatal and exits with status code 1.
 func Fatal(msg any, keyvals ...any) {
-	handler().Fatal(msg, keyvals...)
+	logAt(FatalLevel, fmt.Sprint(msg), keyvals...)
+	Flush()
+	os.Exit(1)
 }
 
 // Fatalf logs a formatted message with level Fatal and exits with status code 1.
 func Fatalf(format string, args ...any) {
-	handler().Fatalf(format, args...)
+	logfAt(FatalLevel, format, args...)
+	Flush()
+	os.Exit(1)
 }
 
 // Print logs a message with no level.
 func Print(msg string, args ...any) {
-	handler().Print(msg, args...)
+	logAt(noLevel, msg, args...)
 }
 
 // Log logs a message with the given level.
 func Log(level Level, msg string, args ...any) {
-	handler().Log(level, msg, args...)
+	logAt(level, msg, args...)
+}
+
+// LogAttrs logs a message with the given level and attributes. Together
+// with the typed attribute helpers such as [String] and [Int], it is the
+// most efficient way to log: it does not allocate when the level is
+// disabled.
+func LogAttrs(level Level, msg string, attrs ...Attr) {
+	logAttrsAt(level, msg, attrs...)
 }
 
 // Logf logs a formatted message with the given level.
 func Logf(level Level, format string, args ...any) {
-	handler().Logf(level, format, args...)
+	logfAt(level, format, args...)
 }
@@ -2,7 +2,10 @@ package log_test
 
 import (
 	"bytes"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"strconv"
 	"testing"
 	"time"
 
@@ -215,8 +218,23 @@ func TestLogFunctions(t *testing.T) {
 	t.Run("Print", func(t *testing.T) {
 		var buf bytes.Buffer
 		log.SetOutput(&buf)
-		log.Print("print message")
-		assert.Contains(t, buf.String(), "print message")
+		log.SetPrefix("")
+		log.SetReportTimestamp(false)
+		log.SetReportCaller(false)
+		for _, tt := range []struct {
+			formatter log.Formatter
+			want      string
+		}{
+			{log.TextFormatter, "print message\n"},
+			{log.JSONFormatter, `{"msg":"print message"}` + "\n"},
+			{log.LogfmtFormatter, `msg="print message"` + "\n"},
+		} {
+			buf.Reset()
+			log.SetFormatter(tt.formatter)
+			log.Print("print message")
+			assert.Equal(t, tt.want, buf.String())
+		}
+		log.SetFormatter(log.TextFormatter)
 	})
 
 	t.Run("Log", func(t *testing.T) {
@@ -235,3 +253,24 @@ func TestLogFunctions(t *testing.T) {
 		assert.Contains(t, buf.String(), "log message")
 	})
 }
+
+func TestEnabled(t *testing.T) {
+	log.SetLevel(log.InfoLevel)
+	assert.False(t, log.Enabled(log.DebugLevel))
+	assert.True(t, log.Enabled(log.InfoLevel))
+	assert.True(t, log.Enabled(log.ErrorLevel))
+}
+
+func TestCallerOffset(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.New(
+		log.UseOutput(&buf),
+		log.UseReportCaller(true),
+		log.UseCallerOffset(1),
+		log.UseCallerFormatter(func(_ string, line int, _ string) string { return strconv.Itoa(line) }),
+	)
+	logHelper := func() { logger.Info("test message") }
+	_, _, line, _ := runtime.Caller(0)
+	logHelper()
+	assert.Equal(t, fmt.Sprintf("INFO <%d> test message\n", line+1), buf.String())
+}
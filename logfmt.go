@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-logfmt/logfmt"
+)
+
+// logfmtFormatter writes the entry as a logfmt record. It must be called
+// with the lock held.
+func (h *handler) logfmtFormatter(b *bytes.Buffer, e entry) {
+	start := b.Len()
+	enc := logfmt.NewEncoder(b)
+	encode := func(key string, val interface{}) {
+		key = h.clean(key)
+		err := enc.EncodeKeyval(key, val)
+		if err != nil && errors.Is(err, logfmt.ErrUnsupportedValueType) {
+			// If the value is not supported by logfmt, we try to convert it to a string.
+			_ = enc.EncodeKeyval(key, fmt.Sprintf("%+v", val))
+		}
+	}
+
+	if e.time.Key != "" {
+		encode(e.time.Key, h.formatTime(e.time.Value))
+	}
+	if e.lvl.Key != "" {
+		if level, ok := e.lvl.Value.Any().(Level); ok {
+			if field, ok := h.levelField(level); ok {
+				encode(e.lvl.Key, field)
+			}
+		} else {
+			encode(e.lvl.Key, e.lvl.Value.Any())
+		}
+	}
+	for _, a := range []slog.Attr{e.caller, e.prefix, e.message} {
+		if a.Key != "" {
+			encode(a.Key, a.Value.Any())
+		}
+	}
+	for _, a := range e.attrs {
+		if a.Key != "" {
+			encode(a.Key, a.Value.Any())
+		}
+	}
+	_ = enc.EndRecord()
+	if h.sanitize {
+		sanitizeLogfmt(b, start)
+	}
+}
@@ -0,0 +1,113 @@
+package log
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// metricKey identifies a counter.
+type metricKey struct {
+	level  Level
+	prefix string
+}
+
+// Metrics counts log records by level and prefix. Records that are emitted
+// and records that are filtered out by the level are counted separately.
+// It is an [http.Handler] serving the counters in the Prometheus text
+// exposition format.
+//
+// Filtered records are counted when the level check of a logger fails,
+// for the package-level functions and the [slog.Logger] methods alike, and
+// when a logger receives a record below its level, such as a record held by
+// a level override. Since the level check cannot tell a logging call from
+// an explicit call to [slog.Logger.Enabled], such calls are counted too.
+type Metrics struct {
+	mu       sync.Mutex
+	emitted  map[metricKey]uint64
+	filtered map[metricKey]uint64
+}
+
+var _ http.Handler = (*Metrics)(nil)
+
+// NewMetrics returns a new set of counters.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		emitted:  make(map[metricKey]uint64),
+		filtered: make(map[metricKey]uint64),
+	}
+}
+
+// Emitted returns the number of emitted records with the level and prefix.
+func (m *Metrics) Emitted(level Level, prefix string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.emitted[metricKey{level, prefix}]
+}
+
+// Filtered returns the number of records with the level and prefix that
+// were filtered out by the level.
+func (m *Metrics) Filtered(level Level, prefix string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.filtered[metricKey{level, prefix}]
+}
+
+// record increments the emitted or filtered counter.
+func (m *Metrics) record(level Level, prefix string, emitted bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if emitted {
+		m.emitted[metricKey{level, prefix}]++
+	} else {
+		m.filtered[metricKey{level, prefix}]++
+	}
+}
+
+// ServeHTTP writes the counters in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = m.WriteTo(w)
+}
+
+// WriteTo writes the counters in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+	m.mu.Lock()
+	writeCounter(&b, "log_records_total",
+		"Number of log records emitted, by level and prefix.", m.emitted)
+	writeCounter(&b, "log_records_filtered_total",
+		"Number of log records filtered out by level, by level and prefix.", m.filtered)
+	m.mu.Unlock()
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// writeCounter writes a counter family sorted by level and prefix.
+func writeCounter(b *strings.Builder, name, help string, counts map[metricKey]uint64) {
+	keys := make([]metricKey, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b metricKey) int {
+		return cmp.Or(cmp.Compare(a.level, b.level), cmp.Compare(a.prefix, b.prefix))
+	})
+
+	fmt.Fprintf(b, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(b, "# TYPE %s counter\n", name)
+	for _, k := range keys {
+		fmt.Fprintf(b, "%s{level=\"%s\",prefix=\"%s\"} %d\n",
+			name, escapeLabel(levelName(k.level)), escapeLabel(k.prefix), counts[k])
+	}
+}
+
+// labelEscaper escapes Prometheus label values.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
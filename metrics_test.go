@@ -0,0 +1,66 @@
+package log_test
+
+import (
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := log.NewMetrics()
+	logger := log.New(log.UseOutput(&discard{}), log.UseMetrics(m))
+	logger.Info("test message")
+	logger.Error("test message")
+	logger.Debug("test message")
+
+	db := log.New(
+		log.UseOutput(&discard{}),
+		log.UseMetrics(m),
+		log.UsePrefix("db"),
+		log.UseLevel(log.WarnLevel),
+	)
+	db.Error("test message")
+	db.With("key", "value").Error("test message")
+	db.Info("test message")
+
+	// The package-level functions count filtered records too.
+	log.New(
+		log.AsDefault(),
+		log.UseOutput(&discard{}),
+		log.UseMetrics(m),
+		log.UsePrefix("db"),
+		log.UseLevel(log.WarnLevel),
+	)
+	t.Cleanup(func() { log.New(log.AsDefault()) })
+	log.Info("test message")
+	log.Infof("test %s", "message")
+	log.LogAttrs(log.DebugLevel, "test message")
+	slog.Info("test message")
+
+	assert.Equal(t, uint64(2), m.Emitted(log.ErrorLevel, "db"))
+	assert.Equal(t, uint64(4), m.Filtered(log.InfoLevel, "db"))
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `# HELP log_records_total Number of log records emitted, by level and prefix.
+# TYPE log_records_total counter
+log_records_total{level="info",prefix=""} 1
+log_records_total{level="error",prefix=""} 1
+log_records_total{level="error",prefix="db"} 2
+# HELP log_records_filtered_total Number of log records filtered out by level, by level and prefix.
+# TYPE log_records_filtered_total counter
+log_records_filtered_total{level="debug",prefix=""} 1
+log_records_filtered_total{level="debug",prefix="db"} 1
+log_records_filtered_total{level="info",prefix="db"} 4
+`, rec.Body.String())
+}
+
+// discard is a writer that discards all writes. Unlike [io.Discard], it does
+// not disable the logger.
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -16,6 +16,7 @@ type (
 	Formatter       = log.Formatter
 	Styles          = log.Styles
 	TimeFunction    = func(time.Time) time.Time
+	ReplaceAttrFunc = func(groups []string, a slog.Attr) slog.Attr
 )
 
 // Levels
@@ -27,7 +28,9 @@ const (
 	FatalLevel = log.FatalLevel
 )
 
-// Caller Formatters
+// Caller Formatters, see also [FunctionCallerFormatter],
+// [PackageCallerFormatter], [CompactCallerFormatter] and
+// [ModuleCallerFormatter].
 var (
 	ShortCallerFormatter = log.ShortCallerFormatter
 	LongCallerFormatter  = log.LongCallerFormatter
@@ -38,18 +41,129 @@ const (
 	TextFormatter   = log.TextFormatter
 	JSONFormatter   = log.JSONFormatter
 	LogfmtFormatter = log.LogfmtFormatter
+
+	// AutoFormatter selects [TextFormatter] when the output is a terminal
+	// and [JSONFormatter] otherwise, each time the output is set. It honors
+	// the NO_COLOR, CLICOLOR_FORCE, FORCE_COLOR and TERM=dumb environment
+	// variables: forcing colors selects the text formatter for any output.
+	AutoFormatter Formatter = 255
 )
 
 //  +------------------------------------------------------------+
 //  | Options 												 	 |
 //  +------------------------------------------------------------+
 
+// Keys are the keys of the built-in fields. The JSON and logfmt formatters
+// use them as field names. A field with an empty key is omitted from the
+// output of all formatters.
+type Keys struct {
+	Time    string // Time is the key for the timestamp. Default is "time".
+	Level   string // Level is the key for the level. Default is "level".
+	Message string // Message is the key for the message. Default is "msg".
+	Caller  string // Caller is the key for the caller location. Default is "caller".
+	Prefix  string // Prefix is the key for the prefix. Default is "prefix".
+}
+
+// DefaultKeys returns the default keys.
+func DefaultKeys() Keys {
+	return Keys{
+		Time:    log.TimestampKey,
+		Level:   log.LevelKey,
+		Message: log.MessageKey,
+		Caller:  log.CallerKey,
+		Prefix:  log.PrefixKey,
+	}
+}
+
 // Options is the logger options.
 type Options struct {
 	*LogOptions
 	Writer  io.Writer   // Writer is the writer for the logger. Default is [os.Stderr].
 	Styles  *log.Styles // Styles is the styles for the logger. Default is [DefaultStyles].
 	Default bool        // Default is whether the logger is the default logger. Default is false.
+	Keys    Keys        // Keys are the keys of the built-in fields. Default is [DefaultKeys].
+
+	// ReplaceAttr is called to rewrite each attribute before it is logged,
+	// with the same semantics as [slog.HandlerOptions.ReplaceAttr]. The
+	// built-in fields are passed with a nil groups argument, using the keys
+	// from [Keys]; the level value is a [Level]. Default is nil.
+	ReplaceAttr ReplaceAttrFunc
+
+	Metrics      *Metrics     // Metrics counts the records by level and prefix. Default is nil.
+	Hooks        []Hook       // Hooks are run for each record before it is written. Default is no hooks.
+	ErrorHandler ErrorHandler // ErrorHandler handles hook errors. Default writes them to [os.Stderr].
+	Ring         *Ring        // Ring retains recent records, including those below the level. Default is nil.
+
+	// FlightRecorder is the minimum level of the records below the level
+	// that are held in the scope of their context, see [WithScope]. The
+	// held records are written when a record with level Error or higher is
+	// logged in the same scope, and discarded when the scope ends. Default
+	// is nil, which disables the flight recorder.
+	FlightRecorder *Level
+
+	// Dedup is the window in which consecutive identical records, with the
+	// same level, prefix, message and attributes, are collapsed. When a run
+	// of repeats ends, a summary record with the repeat count and the first
+	// and last timestamps is written. Call [Flush] before exiting to write
+	// the summary of the current run. Default is 0, which disables it.
+	Dedup time.Duration
+
+	Rules []Rule // Rules drop, route or change the level of records, see [ParseRules]. Default is no rules.
+
+	// LevelOverrides override the level for the records logged from the
+	// matching callers. Default is read from [LevelOverridesEnv].
+	LevelOverrides []LevelOverride
+
+	// Pretty pretty-prints composite values, such as structs, maps and
+	// slices, as indented blocks under the message when they do not fit on
+	// one line. It only applies to the text formatter. Default is nil,
+	// which disables it.
+	Pretty *PrettyOptions
+
+	// Sanitize escapes CR, LF and other control characters in the
+	// messages, keys and values written by the text and logfmt formatters,
+	// so that user data cannot forge records or inject terminal escape
+	// sequences. Multi-line values are then written on one line, with
+	// escaped newlines, instead of as indented blocks. The styling from
+	// [Styles] is not affected. Default is true.
+	Sanitize bool
+
+	// Theme is the theme of the text formatter, see [LookupTheme] and
+	// [LoadTheme]. It takes precedence over Styles. Default is nil.
+	Theme *Theme
+
+	LevelLabels *LevelLabels // LevelLabels customize the level labels, see [FullLevelLabels]. Default is nil.
+	Columns     *Columns     // Columns enable the columnar layout of the text formatter. Default is nil.
+
+	// CallerLink is the URL template of the OSC 8 hyperlinks wrapping the
+	// caller in the text formatter, such as [FileCallerLink] or
+	// [VSCodeCallerLink]. The {path} and {line} placeholders are replaced
+	// with the absolute path and line of the caller. Hyperlinks are only
+	// written to terminals; other outputs get plain text. Default is "",
+	// which disables them.
+	CallerLink string
+
+	// CallerFields is whether the JSON formatter writes the caller as an
+	// object with the file, line and function fields, instead of the
+	// output of the caller formatter. Default is false.
+	CallerFields bool
+
+	// TimeMode selects absolute timestamps, or the time elapsed since the
+	// logger was created or since the previous record. Elapsed times are
+	// computed from the record times, without the time function, and are
+	// passed to ReplaceAttr as durations. Default is [AbsoluteTime].
+	TimeMode TimeMode
+
+	// TimeLocation is the location of absolute timestamps, such as
+	// [time.UTC], applied after the time function. Default is nil, which
+	// keeps the location of the time function, the local time by default.
+	TimeLocation *time.Location
+
+	// Leveler is the minimum level of the records written to the output,
+	// replacing the level of [LogOptions]. It is read for each record, so
+	// a [*slog.LevelVar] can be shared by many loggers and slog handlers to
+	// change their level at once. Default is nil.
+	Leveler slog.Leveler
 }
 
 func (o *Options) Apply(opts ...Option) {
@@ -75,6 +189,20 @@ func UseTimeFormat(f string) Option {
 	}
 }
 
+// UseTimeMode sets the time mode option. Default is [AbsoluteTime].
+func UseTimeMode(m TimeMode) Option {
+	return func(o *Options) {
+		o.TimeMode = m
+	}
+}
+
+// UseTimeLocation sets the time location option. Default is nil.
+func UseTimeLocation(loc *time.Location) Option {
+	return func(o *Options) {
+		o.TimeLocation = loc
+	}
+}
+
 // UseLevel sets the level option. Default is [log.InfoLevel].
 func UseLevel(l Level) Option {
 	return func(o *Options) {
@@ -82,6 +210,13 @@ func UseLevel(l Level) Option {
 	}
 }
 
+// UseLeveler sets the leveler option. Default is nil.
+func UseLeveler(l slog.Leveler) Option {
+	return func(o *Options) {
+		o.Leveler = l
+	}
+}
+
 // UsePrefix sets the prefix option. Default is no prefix.
 func UsePrefix(p string) Option {
 	return func(o *Options) {
@@ -126,7 +261,9 @@ func UseFormatter(f Formatter) Option {
 	}
 }
 
-// UseCallerOffset sets the caller offset option. Default is 0.
+// UseCallerOffset sets the caller offset option, the number of stack frames
+// to skip when reporting the caller, for both the package-level functions
+// and the [slog.Logger] methods. Default is 0.
 func UseCallerOffset(offset int) Option {
 	return func(o *Options) {
 		o.CallerOffset = offset
@@ -140,13 +277,145 @@ func UseOutput(w io.Writer) Option {
 	}
 }
 
-// UseStyles sets the styles option. Default is [DefaultStyles].
+// UseSink sets the writer option to the sink and the formatter option to
+// [JSONFormatter], so that records are shipped as newline-delimited JSON.
+func UseSink(s *Sink) Option {
+	return func(o *Options) {
+		o.Writer = s
+		o.Formatter = JSONFormatter
+	}
+}
+
+// UseStyles sets the styles option. The logger uses a copy of the styles.
+// Default is [DefaultStyles].
 func UseStyles(s *Styles) Option {
 	return func(o *Options) {
 		o.Styles = s
 	}
 }
 
+// UseTheme sets the theme option. Default is nil.
+func UseTheme(t Theme) Option {
+	return func(o *Options) {
+		o.Theme = &t
+	}
+}
+
+// UseLevelLabels sets the level labels option. Default is nil.
+func UseLevelLabels(l LevelLabels) Option {
+	return func(o *Options) {
+		o.LevelLabels = &l
+	}
+}
+
+// UseColumns sets the columns option. Default is nil.
+func UseColumns(c Columns) Option {
+	return func(o *Options) {
+		o.Columns = &c
+	}
+}
+
+// UseCallerLink sets the caller link template. Default is "".
+func UseCallerLink(template string) Option {
+	return func(o *Options) {
+		o.CallerLink = template
+	}
+}
+
+// UseCallerFields sets the caller fields option. Default is false.
+func UseCallerFields(b bool) Option {
+	return func(o *Options) {
+		o.CallerFields = b
+	}
+}
+
+// UseKeys sets the keys of the built-in fields. Default is [DefaultKeys].
+func UseKeys(k Keys) Option {
+	return func(o *Options) {
+		o.Keys = k
+	}
+}
+
+// UseReplaceAttr sets the function used to rewrite or drop attributes.
+// Default is nil.
+func UseReplaceAttr(f ReplaceAttrFunc) Option {
+	return func(o *Options) {
+		o.ReplaceAttr = f
+	}
+}
+
+// UseMetrics sets the metrics option. Default is nil.
+func UseMetrics(m *Metrics) Option {
+	return func(o *Options) {
+		o.Metrics = m
+	}
+}
+
+// UseHooks adds hooks to the hooks option. Default is no hooks.
+func UseHooks(hooks ...Hook) Option {
+	return func(o *Options) {
+		o.Hooks = append(o.Hooks, hooks...)
+	}
+}
+
+// UseErrorHandler sets the error handler option. Default writes errors to
+// [os.Stderr].
+func UseErrorHandler(f ErrorHandler) Option {
+	return func(o *Options) {
+		o.ErrorHandler = f
+	}
+}
+
+// UseRing sets the ring option. Default is nil.
+func UseRing(r *Ring) Option {
+	return func(o *Options) {
+		o.Ring = r
+	}
+}
+
+// UseFlightRecorder sets the flight recorder option. Default is nil.
+func UseFlightRecorder(level Level) Option {
+	return func(o *Options) {
+		o.FlightRecorder = &level
+	}
+}
+
+// UseDedup sets the dedup option. Default is 0.
+func UseDedup(window time.Duration) Option {
+	return func(o *Options) {
+		o.Dedup = window
+	}
+}
+
+// UseRules adds rules to the rules option. Default is no rules.
+func UseRules(rules ...Rule) Option {
+	return func(o *Options) {
+		o.Rules = append(o.Rules, rules...)
+	}
+}
+
+// UseLevelOverrides sets the level overrides option. Default is read from
+// [LevelOverridesEnv].
+func UseLevelOverrides(overrides ...LevelOverride) Option {
+	return func(o *Options) {
+		o.LevelOverrides = overrides
+	}
+}
+
+// UsePretty sets the pretty option. Default is nil.
+func UsePretty(p PrettyOptions) Option {
+	return func(o *Options) {
+		o.Pretty = &p
+	}
+}
+
+// UseSanitize sets the sanitize option. Default is true.
+func UseSanitize(s bool) Option {
+	return func(o *Options) {
+		o.Sanitize = s
+	}
+}
+
 // AsDefault sets the logger as the default logger. Default is false.
 func AsDefault() Option {
 	return func(o *Options) {
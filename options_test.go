@@ -2,11 +2,15 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestApplyOptions(t *testing.T) {
@@ -24,6 +28,7 @@ func TestApplyOptions(t *testing.T) {
 	callerOffset := 2
 	var buf bytes.Buffer
 	styles := DefaultStyles()
+	keys := Keys{Time: "ts"}
 
 	// Apply the options
 	options := &Options{
@@ -41,6 +46,12 @@ func TestApplyOptions(t *testing.T) {
 	UseCallerOffset(callerOffset)(options)
 	UseOutput(&buf)(options)
 	UseStyles(styles)(options)
+	UseKeys(keys)(options)
+	UseReplaceAttr(func(_ []string, a slog.Attr) slog.Attr { return a })(options)
+	metrics := NewMetrics()
+	UseMetrics(metrics)(options)
+	UseHooks(NewHook(func(context.Context, slog.Record) error { return nil }))(options)
+	UseErrorHandler(func(error) {})(options)
 	AsDefault()(options)
 
 	// Verify the options
@@ -56,5 +67,45 @@ func TestApplyOptions(t *testing.T) {
 	assert.Equal(t, callerOffset, options.CallerOffset)
 	assert.Equal(t, &buf, options.Writer)
 	assert.Equal(t, styles, options.Styles)
+	assert.Equal(t, keys, options.Keys)
+	assert.NotNil(t, options.ReplaceAttr)
+	assert.Equal(t, metrics, options.Metrics)
+	assert.Len(t, options.Hooks, 1)
+	assert.NotNil(t, options.ErrorHandler)
 	assert.True(t, options.Default)
 }
+
+func TestDefaultOptionsStyles(t *testing.T) {
+	o := DefaultOptions()
+	require.NotNil(t, o.Styles)
+	o.Styles.Levels[InfoLevel] = o.Styles.Levels[InfoLevel].SetString("INF")
+	assert.Equal(t, "INFO", DefaultOptions().Styles.Levels[InfoLevel].Value())
+
+	var buf bytes.Buffer
+	logger := New(func(opts *Options) { *opts = *o }, UseOutput(&buf))
+	logger.Info("message")
+	assert.Equal(t, "INF message\n", buf.String())
+}
+
+func TestUseThemeAuto(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	re := lipgloss.NewRenderer(f)
+	re.SetHasDarkBackground(false)
+	fileRenderers.Store(fileKey{f.Fd(), f.Name()}, re)
+	t.Cleanup(func() { fileRenderers.Delete(fileKey{f.Fd(), f.Name()}) })
+
+	auto, _ := LookupTheme(ThemeAuto)
+	logger := New(UseOutput(&bytes.Buffer{}), UseTheme(auto))
+	h := loggerHandler(logger)
+	assert.Equal(t, themes[ThemeDark].Styles().Message, h.styles.Message)
+
+	// The theme is resolved again for the new output.
+	SetOutput(f, logger)
+	assert.Equal(t, themes[ThemeLight].Styles().Message, h.styles.Message)
+
+	SetStyles(DefaultStyles(), logger)
+	SetOutput(&bytes.Buffer{}, logger)
+	assert.Equal(t, DefaultStyles().Message, h.styles.Message)
+}
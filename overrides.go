@@ -0,0 +1,158 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// LevelOverridesEnv is the environment variable read by [DefaultOptions]
+// for the level overrides option, see [ParseLevelOverrides].
+const LevelOverridesEnv = "LOG_LEVEL_OVERRIDES"
+
+// LevelOverride sets the level of the records logged from the callers
+// matching a pattern.
+type LevelOverride struct {
+	// Pattern is matched against the caller. It is either a package import
+	// path, e.g. "github.com/acme/svc/internal/db", a package path followed
+	// by "/..." to include its subpackages, or a [path.Match] pattern
+	// matched against the package path joined with the file name, e.g.
+	// "github.com/acme/svc/internal/db/*". A pattern starting with ".../"
+	// matches the trailing elements of the package path or of the file
+	// path, e.g. ".../db" or ".../db/conn.go".
+	Pattern string
+	Level   Level // Level is the level of the matching callers.
+}
+
+// ParseLevelOverrides parses level overrides from a comma or space
+// separated list of pattern=level pairs, e.g.
+//
+//	github.com/acme/svc/internal/db/*=debug,github.com/acme/svc/cache/...=warn
+func ParseLevelOverrides(s string) ([]LevelOverride, error) {
+	var overrides []LevelOverride
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' }) {
+		i := strings.LastIndexByte(f, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("log: invalid level override %q", f)
+		}
+		level, err := ParseLevel(f[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("log: invalid level override %q: %w", f, err)
+		}
+		if _, err := path.Match(f[:i], ""); err != nil {
+			return nil, fmt.Errorf("log: invalid level override %q: %w", f, err)
+		}
+		overrides = append(overrides, LevelOverride{Pattern: f[:i], Level: level})
+	}
+	return overrides, nil
+}
+
+// envLevelOverrides returns the level overrides from [LevelOverridesEnv].
+func envLevelOverrides() []LevelOverride {
+	s := os.Getenv(LevelOverridesEnv)
+	if s == "" {
+		return nil
+	}
+	overrides, err := ParseLevelOverrides(s)
+	if err != nil {
+		defaultErrorHandler(err)
+	}
+	return overrides
+}
+
+// levelOverrides resolves the level of callers, caching it by program
+// counter.
+type levelOverrides struct {
+	overrides []LevelOverride
+	min       Level // min is the lowest overridden level.
+	cache     sync.Map
+}
+
+// overrideResult is a cached resolution.
+type overrideResult struct {
+	level Level
+	ok    bool
+}
+
+// newLevelOverrides returns the resolver for the overrides, or nil if there
+// are none.
+func newLevelOverrides(overrides []LevelOverride) *levelOverrides {
+	if len(overrides) == 0 {
+		return nil
+	}
+	lo := &levelOverrides{overrides: overrides, min: overrides[0].Level}
+	for _, o := range overrides {
+		lo.min = min(lo.min, o.Level)
+	}
+	return lo
+}
+
+// level returns the level of the caller at pc, if it is overridden. The
+// first matching override wins.
+func (lo *levelOverrides) level(pc uintptr) (Level, bool) {
+	if pc == 0 {
+		return 0, false
+	}
+	if v, ok := lo.cache.Load(pc); ok {
+		res := v.(overrideResult)
+		return res.level, res.ok
+	}
+
+	frames := runtime.CallersFrames([]uintptr{pc})
+	f, _ := frames.Next()
+	pkg := funcPackage(f.Function)
+	file := pkg + "/" + path.Base(f.File)
+
+	var res overrideResult
+	for _, o := range lo.overrides {
+		if matchCaller(o.Pattern, pkg, file) {
+			res = overrideResult{level: o.Level, ok: true}
+			break
+		}
+	}
+	lo.cache.Store(pc, res)
+	return res.level, res.ok
+}
+
+// matchCaller reports whether the pattern matches the package or file.
+func matchCaller(pattern, pkg, file string) bool {
+	if pattern == pkg {
+		return true
+	}
+	if tree, ok := strings.CutSuffix(pattern, "/..."); ok {
+		return pkg == tree || strings.HasPrefix(pkg, tree+"/")
+	}
+	if suffix, ok := strings.CutPrefix(pattern, ".../"); ok {
+		return matchSuffix(suffix, pkg) || matchSuffix(suffix, file)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// matchSuffix reports whether the pattern matches the trailing elements of
+// the slash-separated name, as many as the pattern has.
+func matchSuffix(pattern, name string) bool {
+	i := len(name)
+	for range strings.Count(pattern, "/") + 1 {
+		if i < 0 {
+			return false
+		}
+		i = strings.LastIndexByte(name[:i], '/')
+	}
+	ok, _ := path.Match(pattern, name[i+1:])
+	return ok
+}
+
+// funcPackage returns the import path of the package of a fully qualified
+// function name, e.g. "github.com/acme/svc/db" for
+// "github.com/acme/svc/db.(*Conn).Query".
+func funcPackage(fn string) string {
+	slash := strings.LastIndexByte(fn, '/')
+	if dot := strings.IndexByte(fn[slash+1:], '.'); dot >= 0 {
+		return fn[:slash+1+dot]
+	}
+	return fn
+}
@@ -0,0 +1,73 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLevelOverrides(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		spec  string
+		debug bool
+	}{
+		{"Package", "github.com/bartventer/log_test=debug", true},
+		{"FileGlob", "github.com/bartventer/log_test/overrides_*.go=debug", true},
+		{"Subtree", "github.com/bartventer/...=debug", true},
+		{"OtherSubtree", "github.com/bartventer/log/...=debug", false},
+		{"Other", "github.com/acme/svc/internal/db/*=debug", false},
+		{"SuffixFile", ".../log_test/overrides_test.go=debug", true},
+		{"SuffixFileGlob", ".../overrides_*.go=debug", true},
+		{"SuffixPackage", ".../bartventer/log_test=debug", true},
+		{"SuffixOther", ".../db/conn.go=debug", false},
+		{"SuffixPartial", ".../_test/overrides_test.go=debug", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides, err := log.ParseLevelOverrides(tt.spec)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			logger := log.New(log.UseOutput(&buf), log.UseLevelOverrides(overrides...))
+			logger.Debug("test message")
+			assert.Equal(t, tt.debug, buf.Len() > 0)
+		})
+	}
+
+	t.Run("Raise", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf))
+		overrides, err := log.ParseLevelOverrides("github.com/bartventer/log_test=error")
+		require.NoError(t, err)
+		log.SetLevelOverrides(overrides, logger)
+		logger.Warn("test message")
+		assert.Empty(t, buf.String())
+		logger.Error("test message")
+		assert.Contains(t, buf.String(), "test message")
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv(log.LevelOverridesEnv, "github.com/bartventer/log_test=debug")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf))
+		logger.Debug("test message")
+		assert.Contains(t, buf.String(), "test message")
+	})
+}
+
+func TestParseLevelOverrides(t *testing.T) {
+	overrides, err := log.ParseLevelOverrides("a/b=debug, c/...=warn")
+	require.NoError(t, err)
+	assert.Equal(t, []log.LevelOverride{
+		{Pattern: "a/b", Level: log.DebugLevel},
+		{Pattern: "c/...", Level: log.WarnLevel},
+	}, overrides)
+
+	for _, s := range []string{"a/b", "a/b=loud", "=debug", "a/[=debug"} {
+		_, err := log.ParseLevelOverrides(s)
+		assert.Error(t, err, s)
+	}
+}
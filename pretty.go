@@ -0,0 +1,246 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PrettyOptions configure the pretty-printing of composite values by the
+// text formatter.
+type PrettyOptions struct {
+	MaxDepth int // MaxDepth is the maximum nesting depth that is expanded. Default is 5.
+	MaxWidth int // MaxWidth is the width below which values stay on one line. Default is 80.
+}
+
+// withDefaults returns the options with zero fields set to their defaults.
+func (o PrettyOptions) withDefaults() PrettyOptions {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = 5
+	}
+	if o.MaxWidth <= 0 {
+		o.MaxWidth = 80
+	}
+	return o
+}
+
+// prettyIndent is the indentation of each nesting level.
+const prettyIndent = "  "
+
+var rawMessageType = reflect.TypeFor[json.RawMessage]()
+
+// prettyValue returns the styled rendering of a composite value: a struct,
+// map, slice, array or JSON document. The value is rendered on one line if
+// it fits after a key of the given width, or as indented lines otherwise.
+// It returns false if the value is not composite. It must be called with
+// the lock held.
+func (h *handler) prettyValue(x any, keyWidth int) (string, bool) {
+	v := prettyElem(reflect.ValueOf(x))
+	if !isComposite(v) {
+		return "", false
+	}
+	p := prettyPrinter{h: h, o: h.pretty.withDefaults()}
+	if keyWidth+len(separator)+len(p.compact(v, 0, false)) <= p.o.MaxWidth {
+		return p.compact(v, 0, true), true
+	}
+	p.write(v, 0, 0)
+	return p.b.String(), true
+}
+
+// prettyPrinter renders values as indented, styled blocks.
+type prettyPrinter struct {
+	h *handler
+	o PrettyOptions
+	b strings.Builder
+}
+
+// write writes the value, expanding it over multiple lines if it is
+// composite and does not fit in the remaining width from column col.
+func (p *prettyPrinter) write(v reflect.Value, depth, col int) {
+	v = prettyElem(v)
+	if !isComposite(v) {
+		p.b.WriteString(p.scalar(v, true))
+		return
+	}
+	if depth >= p.o.MaxDepth {
+		p.b.WriteString(p.elided(v))
+		return
+	}
+	if col+len(p.compact(v, depth, false)) <= p.o.MaxWidth {
+		p.b.WriteString(p.compact(v, depth, true))
+		return
+	}
+
+	open, close := prettyDelims(v)
+	indent := strings.Repeat(prettyIndent, depth+1)
+	p.b.WriteString(open)
+	p.b.WriteByte('\n')
+	p.each(v, func(key string, elem reflect.Value) {
+		p.b.WriteString(indent)
+		n := len(indent)
+		if key != "" {
+			p.b.WriteString(p.key(key))
+			p.b.WriteString(": ")
+			n += len(key) + 2
+		}
+		p.write(elem, depth+1, n)
+		p.b.WriteString(",\n")
+	})
+	p.b.WriteString(strings.Repeat(prettyIndent, depth))
+	p.b.WriteString(close)
+}
+
+// compact returns the value on one line, optionally styled.
+func (p *prettyPrinter) compact(v reflect.Value, depth int, styled bool) string {
+	v = prettyElem(v)
+	if !isComposite(v) {
+		return p.scalar(v, styled)
+	}
+	if depth >= p.o.MaxDepth {
+		return p.elided(v)
+	}
+	var b strings.Builder
+	open, close := prettyDelims(v)
+	b.WriteString(open)
+	first := true
+	p.each(v, func(key string, elem reflect.Value) {
+		if !first {
+			b.WriteString(", ")
+		}
+		first = false
+		if key != "" {
+			if styled {
+				key = p.key(key)
+			}
+			b.WriteString(key)
+			b.WriteString(": ")
+		}
+		b.WriteString(p.compact(elem, depth+1, styled))
+	})
+	b.WriteString(close)
+	return b.String()
+}
+
+// each calls f for each field, entry or element of the composite value.
+// Map entries are sorted by key.
+func (p *prettyPrinter) each(v reflect.Value, f func(key string, elem reflect.Value)) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := range v.NumField() {
+			f(t.Field(i).Name, v.Field(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(prettyElem(k))
+		}
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		slices.SortFunc(idx, func(a, b int) int { return strings.Compare(names[a], names[b]) })
+		for _, i := range idx {
+			f(names[i], v.MapIndex(keys[i]))
+		}
+	default:
+		for i := range v.Len() {
+			f("", v.Index(i))
+		}
+	}
+}
+
+// scalar returns the string representation of a non-composite value.
+func (p *prettyPrinter) scalar(v reflect.Value, styled bool) string {
+	var s string
+	switch {
+	case !v.IsValid():
+		s = "nil"
+	case v.CanInterface() && isStringerOrError(v.Interface()):
+		s = strconv.Quote(formatValue(slog.AnyValue(v.Interface())))
+	case v.Kind() == reflect.String:
+		s = strconv.Quote(v.String())
+	default:
+		s = fmt.Sprintf("%+v", v)
+	}
+	if styled {
+		s = p.h.styles.Value.Renderer(p.h.re).Render(s)
+	}
+	return s
+}
+
+// key returns the styled key.
+func (p *prettyPrinter) key(key string) string {
+	return p.h.renderKey(p.h.clean(key))
+}
+
+// elided returns the placeholder of a composite value beyond the maximum
+// depth.
+func (p *prettyPrinter) elided(v reflect.Value) string {
+	open, close := prettyDelims(v)
+	return open + "…" + close
+}
+
+// prettyElem dereferences pointers and interfaces, and decodes JSON
+// documents.
+func prettyElem(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		if v.CanInterface() && isStringerOrError(v.Interface()) {
+			return v
+		}
+		v = v.Elem()
+	}
+	if v.IsValid() && v.Type() == rawMessageType {
+		var x any
+		if err := json.Unmarshal(v.Bytes(), &x); err == nil {
+			return reflect.ValueOf(x)
+		}
+	}
+	return v
+}
+
+// isComposite reports whether the value is expanded by the pretty printer.
+func isComposite(v reflect.Value) bool {
+	if !v.IsValid() || (v.CanInterface() && isStringerOrError(v.Interface())) {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return true
+	case reflect.Map, reflect.Slice:
+		return !v.IsNil() && v.Type().Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return v.Type().Elem().Kind() != reflect.Uint8
+	default:
+		return false
+	}
+}
+
+// isStringerOrError reports whether x is formatted as a string.
+func isStringerOrError(x any) bool {
+	switch x.(type) {
+	case error, fmt.Stringer:
+		return true
+	default:
+		return false
+	}
+}
+
+// prettyDelims returns the opening and closing delimiters of a composite
+// value.
+func prettyDelims(v reflect.Value) (string, string) {
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "{", "}"
+	default:
+		return "[", "]"
+	}
+}
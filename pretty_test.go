@@ -0,0 +1,66 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+type prettyUser struct {
+	Name string
+	Tags []string
+	Meta map[string]any
+}
+
+func TestPretty(t *testing.T) {
+	user := prettyUser{
+		Name: "gopher",
+		Tags: []string{"admin", "ops"},
+		Meta: map[string]any{"city": "Cape Town", "age": 42, "address": map[string]string{"street": "Long Street"}},
+	}
+
+	t.Run("Expanded", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UsePretty(log.PrettyOptions{MaxWidth: 40}))
+		logger.Info("message", "user", user, "n", 1)
+		want := "INFO message\n" +
+			"  user=\n" +
+			"  │ {\n" +
+			"  │   Name: \"gopher\",\n" +
+			"  │   Tags: [\"admin\", \"ops\"],\n" +
+			"  │   Meta: {\n" +
+			"  │     address: {street: \"Long Street\"},\n" +
+			"  │     age: 42,\n" +
+			"  │     city: \"Cape Town\",\n" +
+			"  │   },\n" +
+			"  │ }\n" +
+			" n=1\n"
+		assert.Equal(t, want, buf.String())
+	})
+
+	t.Run("Inline", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UsePretty(log.PrettyOptions{}))
+		logger.Info("message", "tags", user.Tags, "raw", json.RawMessage(`{"a":[1,true]}`))
+		assert.Equal(t, "INFO message tags=[\"admin\", \"ops\"] raw={a: [1, true]}\n", buf.String())
+	})
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UsePretty(log.PrettyOptions{MaxDepth: 1}))
+		logger.Info("message", "user", user)
+		assert.Equal(t, "INFO message user={Name: \"gopher\", Tags: […], Meta: {…}}\n", buf.String())
+	})
+
+	t.Run("SingleLineFormatters", func(t *testing.T) {
+		for _, f := range []log.Formatter{log.JSONFormatter, log.LogfmtFormatter} {
+			var buf bytes.Buffer
+			logger := log.New(log.UseOutput(&buf), log.UseFormatter(f), log.UsePretty(log.PrettyOptions{MaxWidth: 10}))
+			logger.Info("message", "user", user)
+			assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("\n")), buf.String())
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RingRecord is a record retained by a [Ring].
+type RingRecord struct {
+	Time    time.Time
+	Level   Level
+	Prefix  string
+	Message string
+	Attrs   []slog.Attr // Attrs are the group-qualified attributes, including those of the logger.
+
+	sanitize bool // sanitize is whether the logger sanitizes its output, see [Options.Sanitize].
+}
+
+// Ring retains the most recent records in memory, including records below
+// the level of the logger, down to the level of the ring. It is an
+// [http.Handler] serving the retained records, for use on a debug mux.
+//
+// The handler accepts the query parameters:
+//
+//   - format: "text" (default) or "json"
+//   - level: the minimum level, e.g. "warn"
+//   - prefix: the exact prefix
+//   - attr: a "key=value" attribute the record must have; may be repeated
+type Ring struct {
+	level Level
+
+	mu    sync.Mutex
+	buf   []RingRecord
+	next  int
+	count int
+}
+
+var _ http.Handler = (*Ring)(nil)
+
+// NewRing returns a ring retaining the last size records with a level of
+// at least level.
+func NewRing(size int, level Level) *Ring {
+	return &Ring{level: level, buf: make([]RingRecord, max(size, 1))}
+}
+
+// enabled reports whether records at the given level are retained.
+func (r *Ring) enabled(level Level) bool {
+	return level >= r.level
+}
+
+// add retains the record, evicting the oldest one if the ring is full.
+func (r *Ring) add(rec RingRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = rec
+	r.next = (r.next + 1) % len(r.buf)
+	r.count = min(r.count+1, len(r.buf))
+}
+
+// Records returns the retained records, oldest first.
+func (r *Ring) Records() []RingRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	recs := make([]RingRecord, 0, r.count)
+	start := (r.next - r.count + len(r.buf)) % len(r.buf)
+	for i := range r.count {
+		recs = append(recs, r.buf[(start+i)%len(r.buf)])
+	}
+	return recs
+}
+
+// ServeHTTP writes the retained records matching the query filters.
+func (r *Ring) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	match := func(RingRecord) bool { return true }
+
+	if s := q.Get("level"); s != "" {
+		level, err := ParseLevel(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		prev := match
+		match = func(rec RingRecord) bool { return prev(rec) && rec.Level >= level }
+	}
+	if q.Has("prefix") {
+		prefix := q.Get("prefix")
+		prev := match
+		match = func(rec RingRecord) bool { return prev(rec) && rec.Prefix == prefix }
+	}
+	for _, s := range q["attr"] {
+		key, val, ok := strings.Cut(s, "=")
+		if !ok {
+			http.Error(w, fmt.Sprintf("invalid attr filter %q", s), http.StatusBadRequest)
+			return
+		}
+		prev := match
+		match = func(rec RingRecord) bool { return prev(rec) && hasAttr(rec.Attrs, key, val) }
+	}
+
+	var recs []RingRecord
+	for _, rec := range r.Records() {
+		if match(rec) {
+			recs = append(recs, rec)
+		}
+	}
+
+	if q.Get("format") == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		out := make([]map[string]any, 0, len(recs))
+		for _, rec := range recs {
+			attrs := make(map[string]any, len(rec.Attrs))
+			for _, a := range rec.Attrs {
+				attrs[a.Key] = jsonValue(a.Value)
+			}
+			out = append(out, map[string]any{
+				"time":   rec.Time,
+				"level":  levelName(rec.Level),
+				"prefix": rec.Prefix,
+				"msg":    rec.Message,
+				"attrs":  attrs,
+			})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		_ = enc.Encode(out)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	var b strings.Builder
+	for _, rec := range recs {
+		b.WriteString(rec.Time.Format(time.RFC3339Nano))
+		b.WriteByte(' ')
+		b.WriteString(strings.ToUpper(levelName(rec.Level)))
+		if rec.Prefix != "" {
+			b.WriteString(" " + rec.clean(rec.Prefix) + ":")
+		}
+		b.WriteString(" " + rec.clean(rec.Message))
+		for _, a := range rec.Attrs {
+			val := formatValue(a.Value)
+			if needsQuoting(val) {
+				val = `"` + escapeStringForOutput(val, true) + `"`
+			}
+			b.WriteString(" " + rec.clean(a.Key) + "=" + val)
+		}
+		b.WriteByte('\n')
+	}
+	_, _ = w.Write([]byte(b.String()))
+}
+
+// clean returns s sanitized if the logger of the record sanitizes its
+// output.
+func (rec RingRecord) clean(s string) string {
+	if !rec.sanitize {
+		return s
+	}
+	return sanitize(s)
+}
+
+// hasAttr reports whether attrs contains an attribute with the key and the
+// formatted value.
+func hasAttr(attrs []slog.Attr, key, val string) bool {
+	for _, a := range attrs {
+		if a.Key == key && formatValue(a.Value) == val {
+			return true
+		}
+	}
+	return false
+}
+
+// ringRecord returns the record to retain in the ring.
+func (h *handler) ringRecord(r slog.Record) RingRecord {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	rec := RingRecord{
+		Time:    r.Time,
+		Level:   Level(r.Level),
+		Prefix:  h.prefix,
+		Message: r.Message,
+		Attrs:   make([]slog.Attr, 0, len(h.attrs)+r.NumAttrs()),
+
+		sanitize: h.sanitize,
+	}
+	rec.Attrs = append(rec.Attrs, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		rec.Attrs = h.appendAttrs(rec.Attrs, h.groups, a)
+		return true
+	})
+	return rec
+}
@@ -0,0 +1,72 @@
+package log_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRing(t *testing.T) {
+	var buf bytes.Buffer
+	ring := log.NewRing(3, log.DebugLevel)
+	logger := log.New(log.UseOutput(&buf), log.UseRing(ring))
+
+	logger.Debug("one")
+	logger.Info("two", "user", "alice")
+	db := log.WithPrefix(log.New(log.UseOutput(&buf), log.UseRing(ring)), "db")
+	db.Debug("three", "user", "bob")
+	db.Error("four")
+
+	// Debug records are retained but not written.
+	assert.NotContains(t, buf.String(), "three")
+
+	recs := ring.Records()
+	require.Len(t, recs, 3)
+	assert.Equal(t, "two", recs[0].Message)
+	assert.Equal(t, "four", recs[2].Message)
+	assert.Equal(t, "db", recs[1].Prefix)
+
+	serve := func(query string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs?"+query, nil))
+		return rec
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		rec := serve("prefix=db&attr=user=bob")
+		assert.Regexp(t, `^\S+ DEBUG db: three user=bob\n$`, rec.Body.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		rec := serve("format=json&level=info")
+		var out []map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &out))
+		require.Len(t, out, 2)
+		assert.Equal(t, "two", out[0]["msg"])
+		assert.Equal(t, map[string]any{"user": "alice"}, out[0]["attrs"])
+		assert.Equal(t, "error", out[1]["level"])
+	})
+
+	t.Run("BadRequest", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("level=loud").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("attr=user").Code)
+	})
+}
+
+func TestRingSanitize(t *testing.T) {
+	ring := log.NewRing(2, log.DebugLevel)
+	log.WithPrefix(log.New(log.UseOutput(&bytes.Buffer{}), log.UseRing(ring)), "db\x1b[2J").
+		Info("one\n2024-01-01 INFO forged", "k\r", "v")
+	log.New(log.UseOutput(&bytes.Buffer{}), log.UseRing(ring), log.UseSanitize(false)).
+		Info("two\x1b[0m")
+
+	rec := httptest.NewRecorder()
+	ring.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/logs", nil))
+	assert.Regexp(t, `^\S+ INFO db\\x1b\[2J: one\\n2024-01-01 INFO forged k\\r=v\n\S+ INFO two\x1b\[0m\n$`, rec.Body.String())
+}
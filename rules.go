@@ -0,0 +1,258 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+// Condition is a condition of a [Rule] on a field of a record.
+type Condition struct {
+	// Field is "level", "prefix", "msg", or the group-qualified key of an
+	// attribute.
+	Field string
+	// Op is one of "=", "!=" and "~" (contains). For the level, it may also
+	// be one of "<", "<=", ">" and ">=".
+	Op string
+	// Value is the value to compare the field with. For the level, it is a
+	// level name.
+	Value string
+}
+
+// Rule drops, routes or changes the level of the records matching all its
+// conditions. Rules are applied in order before the level check, so that
+// a rule can raise records from below the level. Routed records are then
+// subject to the level of their route, or to the level of the logger if the
+// route has none.
+type Rule struct {
+	When  []Condition // When are the conditions. A rule without conditions matches all records.
+	Drop  bool        // Drop is whether matching records are discarded.
+	Route io.Writer   // Route is the writer matching records are written to, instead of the output.
+	Level *Level      // Level is the new level of matching records.
+
+	// RouteLevel is the minimum level of the records written to Route,
+	// which may be below the level of the logger. Default is nil, which
+	// uses the level of the logger.
+	RouteLevel *Level
+}
+
+// ruleOps are the operators, longest first so that they are matched
+// before their prefixes.
+var ruleOps = []string{"!=", "<=", ">=", "=", "~", "<", ">"}
+
+// ParseRules parses rules from a string. Rules are separated by newlines
+// or semicolons and have the form
+//
+//	<condition>... => <action>
+//
+// where each condition is a field, an operator and a value, e.g.
+// "level<=debug", "prefix=payment" or "component=cache", and the action is
+// "drop", "level=<level>" or "route=<name>", with the name looked up in
+// writers. A route may be followed by its level, as in "route=audit@debug",
+// see [Rule.RouteLevel]. For example:
+//
+//	level=debug component=cache => drop
+//	audit=true => route=audit@debug
+//	prefix=payment => level=warn
+func ParseRules(s string, writers map[string]io.Writer) ([]Rule, error) {
+	var rules []Rule
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ';' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		conds, action, ok := strings.Cut(line, "=>")
+		if !ok {
+			return nil, fmt.Errorf("log: invalid rule %q: missing =>", line)
+		}
+
+		var rule Rule
+		for _, f := range strings.Fields(conds) {
+			c, err := parseCondition(f)
+			if err != nil {
+				return nil, fmt.Errorf("log: invalid rule %q: %w", line, err)
+			}
+			rule.When = append(rule.When, c)
+		}
+
+		action = strings.TrimSpace(action)
+		name, arg, _ := strings.Cut(action, "=")
+		switch name {
+		case "drop":
+			rule.Drop = true
+		case "level":
+			level, err := ParseLevel(arg)
+			if err != nil {
+				return nil, fmt.Errorf("log: invalid rule %q: %w", line, err)
+			}
+			rule.Level = &level
+		case "route":
+			arg, lvl, hasLevel := strings.Cut(arg, "@")
+			w, ok := writers[arg]
+			if !ok {
+				return nil, fmt.Errorf("log: invalid rule %q: unknown writer %q", line, arg)
+			}
+			rule.Route = w
+			if hasLevel {
+				level, err := ParseLevel(lvl)
+				if err != nil {
+					return nil, fmt.Errorf("log: invalid rule %q: %w", line, err)
+				}
+				rule.RouteLevel = &level
+			}
+		default:
+			return nil, fmt.Errorf("log: invalid rule %q: unknown action %q", line, action)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseCondition parses a condition such as "level>=warn".
+func parseCondition(s string) (Condition, error) {
+	i := strings.IndexAny(s, "!<>=~")
+	if i <= 0 {
+		return Condition{}, fmt.Errorf("invalid condition %q", s)
+	}
+	for _, op := range ruleOps {
+		if strings.HasPrefix(s[i:], op) {
+			c := Condition{Field: s[:i], Op: op, Value: s[i+len(op):]}
+			if c.Field == "level" {
+				if _, err := ParseLevel(c.Value); err != nil {
+					return Condition{}, err
+				}
+			} else if !slices.Contains([]string{"=", "!=", "~"}, op) {
+				return Condition{}, fmt.Errorf("invalid operator %q for %q", op, c.Field)
+			}
+			return c, nil
+		}
+	}
+	return Condition{}, fmt.Errorf("invalid condition %q", s)
+}
+
+// ruleSubject is the view of a record the conditions are evaluated on.
+type ruleSubject struct {
+	level  Level
+	prefix string
+	msg    string
+	attrs  []slog.Attr
+}
+
+// match reports whether the condition holds for the subject.
+func (c Condition) match(s *ruleSubject) bool {
+	switch c.Field {
+	case "level":
+		want, _ := ParseLevel(c.Value)
+		switch c.Op {
+		case "=":
+			return s.level == want
+		case "!=":
+			return s.level != want
+		case "<":
+			return s.level < want
+		case "<=":
+			return s.level <= want
+		case ">":
+			return s.level > want
+		case ">=":
+			return s.level >= want
+		}
+		return false
+	case "prefix":
+		return compare(c.Op, s.prefix, c.Value, true)
+	case "msg":
+		return compare(c.Op, s.msg, c.Value, true)
+	}
+	for _, a := range s.attrs {
+		if a.Key == c.Field {
+			return compare(c.Op, formatValue(a.Value), c.Value, true)
+		}
+	}
+	return compare(c.Op, "", c.Value, false)
+}
+
+// compare compares the field value with the condition value. A missing
+// field only satisfies "!=".
+func compare(op, got, want string, present bool) bool {
+	switch op {
+	case "=":
+		return present && got == want
+	case "!=":
+		return !present || got != want
+	case "~":
+		return present && strings.Contains(got, want)
+	}
+	return false
+}
+
+// applyRules applies the rules to the record. It returns the record with
+// its level possibly changed, the rule routing it, if any, and whether it
+// is dropped.
+func (h *handler) applyRules(r slog.Record) (slog.Record, *Rule, bool) {
+	h.mu.RLock()
+	rules := h.rules
+	s := ruleSubject{level: Level(r.Level), prefix: h.prefix, msg: r.Message}
+	needAttrs := slices.ContainsFunc(rules, func(rule Rule) bool {
+		return slices.ContainsFunc(rule.When, func(c Condition) bool {
+			return c.Field != "level" && c.Field != "prefix" && c.Field != "msg"
+		})
+	})
+	if needAttrs {
+		s.attrs = append([]slog.Attr(nil), h.attrs...)
+		r.Attrs(func(a slog.Attr) bool {
+			s.attrs = h.appendAttrs(s.attrs, h.groups, a)
+			return true
+		})
+	}
+	h.mu.RUnlock()
+
+	for i, rule := range rules {
+		if !slices.ContainsFunc(rule.When, func(c Condition) bool { return !c.match(&s) }) {
+			switch {
+			case rule.Drop:
+				return r, nil, true
+			case rule.Route != nil:
+				r.Level = slog.Level(s.level)
+				return r, &rules[i], false
+			case rule.Level != nil:
+				s.level = *rule.Level
+			}
+		}
+	}
+	r.Level = slog.Level(s.level)
+	return r, nil, false
+}
+
+// rulesHandle reports whether a rule may raise records at the given level
+// to the level of the handler, or route them to a route with a lower
+// level, so that they must be handled. Only the level conditions of the
+// rules are evaluated.
+func (h *handler) rulesHandle(level Level) bool {
+	if len(h.rules) == 0 {
+		return false
+	}
+	minLevel := h.minLevel()
+	s := ruleSubject{level: level}
+	for _, rule := range h.rules {
+		switch {
+		case rule.Level != nil && *rule.Level > level && *rule.Level >= minLevel:
+		case rule.Route != nil && rule.RouteLevel != nil && level >= *rule.RouteLevel && level < minLevel:
+		default:
+			continue
+		}
+		if !slices.ContainsFunc(rule.When, func(c Condition) bool { return c.Field == "level" && !c.match(&s) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeEnabled reports whether the record routed by the rule is written.
+func (h *handler) routeEnabled(rule *Rule, level Level, pc uintptr) bool {
+	if rule.RouteLevel != nil {
+		return level >= *rule.RouteLevel
+	}
+	return h.enabledAt(level, pc)
+}
@@ -0,0 +1,134 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	var out, audit bytes.Buffer
+	rules, err := log.ParseRules(`
+		# noisy cache
+		level=debug component=cache => drop
+		audit=true => route=audit; prefix=payment => level=warn
+	`, map[string]io.Writer{"audit": &audit})
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+
+	logger := log.New(
+		log.UseOutput(&out),
+		log.UseFormatter(log.LogfmtFormatter),
+		log.UseLevel(log.DebugLevel),
+		log.UseRules(rules...),
+	)
+	logger.Debug("miss", "component", "cache")
+	logger.Debug("query", "component", "db")
+	logger.Info("login", "audit", true)
+
+	payments := log.New(
+		log.UseOutput(&out),
+		log.UseFormatter(log.LogfmtFormatter),
+		log.UsePrefix("payment"),
+		log.UseRules(rules...),
+	)
+	payments.Debug("charged")
+
+	assert.Equal(t, "level=debug msg=query component=db\nlevel=warn prefix=payment msg=charged\n", out.String())
+	assert.Equal(t, "level=info msg=login audit=true\n", audit.String())
+}
+
+func TestParseRules(t *testing.T) {
+	for _, s := range []string{
+		"level=debug",
+		"level=loud => drop",
+		"prefix>=a => drop",
+		"=x => drop",
+		"prefix=a => explode",
+		"prefix=a => route=missing",
+		"prefix=a => level=loud",
+	} {
+		_, err := log.ParseRules(s, nil)
+		assert.Error(t, err, s)
+	}
+
+	rules, err := log.ParseRules("msg~timeout user!=root => drop", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []log.Rule{{
+		When: []log.Condition{
+			{Field: "msg", Op: "~", Value: "timeout"},
+			{Field: "user", Op: "!=", Value: "root"},
+		},
+		Drop: true,
+	}}, rules)
+}
+
+func TestRulesLevel(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Route", func(t *testing.T) {
+		var out, audit bytes.Buffer
+		rules, err := log.ParseRules("audit=true => route=audit", map[string]io.Writer{"audit": &audit})
+		require.NoError(t, err)
+		logger := log.New(log.UseOutput(&out), log.UseFormatter(log.LogfmtFormatter), log.UseRules(rules...))
+
+		assert.False(t, logger.Enabled(ctx, slog.LevelDebug))
+		evaluated := false
+		logger.Debug("hidden", "audit", true, "lazy", log.Lazy(func() any {
+			evaluated = true
+			return "value"
+		}))
+		require.NoError(t, logger.Handler().Handle(ctx, slog.NewRecord(time.Time{}, slog.LevelDebug, "hidden", 0)))
+		logger.Info("shown", "audit", true)
+
+		assert.False(t, evaluated)
+		assert.Empty(t, out.String())
+		assert.Equal(t, "level=info msg=shown audit=true\n", audit.String())
+	})
+
+	t.Run("RouteLevel", func(t *testing.T) {
+		var out, audit bytes.Buffer
+		writers := map[string]io.Writer{"audit": &audit}
+		_, err := log.ParseRules("audit=true => route=audit@loud", writers)
+		require.Error(t, err)
+		rules, err := log.ParseRules("audit=true => route=audit@debug", writers)
+		require.NoError(t, err)
+		logger := log.New(log.UseOutput(&out), log.UseFormatter(log.LogfmtFormatter), log.UseRules(rules...))
+
+		assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+		logger.Debug("access", "audit", true)
+		logger.Debug("hidden", "audit", false)
+		logger.Info("shown")
+
+		assert.Equal(t, "level=info msg=shown\n", out.String())
+		assert.Equal(t, "level=debug msg=access audit=true\n", audit.String())
+	})
+
+	t.Run("Lower", func(t *testing.T) {
+		var out bytes.Buffer
+		rules, err := log.ParseRules("prefix=noisy => level=debug", nil)
+		require.NoError(t, err)
+		logger := log.New(log.UseOutput(&out), log.UseRules(rules...))
+		assert.False(t, logger.Enabled(ctx, slog.LevelDebug))
+		assert.True(t, logger.Enabled(ctx, slog.LevelInfo))
+	})
+
+	t.Run("Raise", func(t *testing.T) {
+		var out bytes.Buffer
+		rules, err := log.ParseRules("level=debug component=db => level=warn", nil)
+		require.NoError(t, err)
+		logger := log.New(log.UseOutput(&out), log.UseFormatter(log.LogfmtFormatter), log.UseLevel(log.WarnLevel), log.UseRules(rules...))
+		assert.True(t, logger.Enabled(ctx, slog.LevelDebug))
+		assert.False(t, logger.Enabled(ctx, slog.LevelInfo))
+		logger.Debug("query", "component", "db")
+		logger.Debug("miss", "component", "cache")
+		assert.Equal(t, "level=warn msg=query component=db\n", out.String())
+	})
+}
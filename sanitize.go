@@ -0,0 +1,92 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// The sanitizer protects the text and logfmt outputs against log injection:
+// user data with line breaks could forge records, and user data with ANSI
+// or OSC escape sequences could rewrite the terminal. Control characters
+// are escaped, which also defuses escape sequences, since they all start
+// with the ESC control character or a C1 control character.
+
+// isUnsafeRune reports whether r is a C0 or C1 control character, or DEL.
+func isUnsafeRune(r rune) bool {
+	return r < 0x20 || (r >= 0x7f && r <= 0x9f)
+}
+
+// sanitize returns s with CR, LF, C0 and C1 control characters and invalid
+// UTF-8 bytes escaped.
+func sanitize(s string) string {
+	i := 0
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if isUnsafeRune(r) || (r == utf8.RuneError && size == 1) {
+			break
+		}
+		i += size
+	}
+	if i == len(s) {
+		return s
+	}
+
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	b.WriteString(s[:i])
+	for i < len(s) {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		invalid := r == utf8.RuneError && size == 1
+		switch {
+		case !invalid && !isUnsafeRune(r):
+			b.WriteString(s[i : i+size])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case invalid || r < utf8.RuneSelf:
+			b.WriteString(`\x`)
+			b.WriteByte(lowerhex[s[i]>>4])
+			b.WriteByte(lowerhex[s[i]&0xF])
+		default:
+			b.WriteString(`\u00`)
+			b.WriteByte(lowerhex[r>>4])
+			b.WriteByte(lowerhex[r&0xF])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+// clean returns s sanitized if sanitization is enabled. It must be called
+// with the lock held.
+func (h *handler) clean(s string) string {
+	if !h.sanitize {
+		return s
+	}
+	return sanitize(s)
+}
+
+// sanitizeLogfmt escapes the DEL and C1 control characters that the logfmt
+// encoder writes verbatim, in the bytes of b after start. The encoder
+// already quotes and escapes the C0 control characters.
+func sanitizeLogfmt(b *bytes.Buffer, start int) {
+	verbatim := func(r rune) bool { return r >= 0x7f && r <= 0x9f }
+	if !bytes.ContainsFunc(b.Bytes()[start:], verbatim) {
+		return
+	}
+	s := string(b.Bytes()[start:])
+	b.Truncate(start)
+	for _, r := range s {
+		if verbatim(r) {
+			b.WriteString(`\u00`)
+			b.WriteByte(lowerhex[r>>4])
+			b.WriteByte(lowerhex[r&0xF])
+		} else {
+			b.WriteRune(r)
+		}
+	}
+}
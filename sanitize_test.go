@@ -0,0 +1,46 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	const forged = "done\nERROR forged record"
+	const ansi = "\x1b[2J\x1b]0;title\x07red\u009b31m"
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UsePrefix("p\rx"))
+		logger.Info(forged, "k\ney", ansi, "n", 1)
+		assert.Equal(t,
+			`INFO p\rx: done\nERROR forged record k\ney="\x1b[2J\x1b]0;title\ared\u009b31m" n=1`+"\n",
+			buf.String())
+	})
+
+	t.Run("MultiLineValue", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf))
+		logger.Info("done", "v", "a\nINFO forged", "n", 1)
+		assert.Equal(t, `INFO done v="a\nINFO forged" n=1`+"\n", buf.String())
+	})
+
+	t.Run("Logfmt", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.LogfmtFormatter))
+		logger.Info(forged, "k\ney", ansi, "del", "a\x7fb")
+		assert.Equal(t,
+			`level=info msg="done\nERROR forged record" k\ney="\u001b[2J\u001b]0;title\u0007red\u009b31m" del=a\u007fb`+"\n",
+			buf.String())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseSanitize(false))
+		logger.Info(forged, "v", "a\nb")
+		assert.Equal(t, "INFO "+forged+"\n  v=\n  │ a\n  │ b\n", buf.String())
+	})
+}
@@ -3,8 +3,7 @@ package log
 import (
 	"io"
 	"log/slog"
-
-	"github.com/charmbracelet/log"
+	"time"
 )
 
 // applyToLoggers applies a given setting function to the provided loggers.
@@ -40,10 +39,19 @@ func SetFormatter(f Formatter, loggers ...*slog.Logger) {
 	}, loggers...)
 }
 
-// SetLevel sets the level.
+// SetLevel sets the level. It replaces the leveler of the loggers, but
+// does not change the leveler itself.
 func SetLevel(level Level, loggers ...*slog.Logger) {
 	applyToLoggers(func(l *slog.Logger) {
-		loggerHandler(l).SetLevel(log.Level(level))
+		loggerHandler(l).SetLevel(level)
+	}, loggers...)
+}
+
+// SetLeveler sets the leveler, see [Options.Leveler]. A nil leveler
+// restores the level.
+func SetLeveler(leveler slog.Leveler, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetLeveler(leveler)
 	}, loggers...)
 }
 
@@ -67,7 +75,7 @@ func WithPrefix(l *slog.Logger, prefix string) *slog.Logger {
 	return l
 }
 
-// SetStyles sets the logger styles.
+// SetStyles sets the logger styles. Each logger uses a copy of the styles.
 func SetStyles(s *Styles, loggers ...*slog.Logger) {
 	applyToLoggers(func(l *slog.Logger) {
 		loggerHandler(l).SetStyles(s)
@@ -95,9 +103,60 @@ func SetTimeFormat(format string, loggers ...*slog.Logger) {
 	}, loggers...)
 }
 
+// SetTimeMode sets the time mode.
+func SetTimeMode(m TimeMode, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetTimeMode(m)
+	}, loggers...)
+}
+
+// SetTimeLocation sets the time location. A nil location keeps the
+// location of the time function.
+func SetTimeLocation(loc *time.Location, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetTimeLocation(loc)
+	}, loggers...)
+}
+
 // SetTimeFunction sets the time function.
 func SetTimeFunction(f TimeFunction, loggers ...*slog.Logger) {
 	applyToLoggers(func(l *slog.Logger) {
 		loggerHandler(l).SetTimeFunction(f)
 	}, loggers...)
 }
+
+// SetKeys sets the keys of the built-in fields.
+func SetKeys(k Keys, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetKeys(k)
+	}, loggers...)
+}
+
+// AddHook adds a hook that is run for each record before it is written.
+func AddHook(hook Hook, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).AddHook(hook)
+	}, loggers...)
+}
+
+// Flush writes the pending summary records of the dedup option. It is
+// called by [Fatal] and [Fatalf] before exiting.
+func Flush(loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).Flush()
+	}, loggers...)
+}
+
+// SetErrorHandler sets the handler for hook errors.
+func SetErrorHandler(f ErrorHandler, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetErrorHandler(f)
+	}, loggers...)
+}
+
+// SetLevelOverrides sets the level overrides, see [ParseLevelOverrides].
+func SetLevelOverrides(overrides []LevelOverride, loggers ...*slog.Logger) {
+	applyToLoggers(func(l *slog.Logger) {
+		loggerHandler(l).SetLevelOverrides(overrides)
+	}, loggers...)
+}
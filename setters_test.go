@@ -2,6 +2,7 @@ package log_test
 
 import (
 	"bytes"
+	"io"
 	"testing"
 	"time"
 
@@ -52,6 +53,24 @@ func TestSetOutput(t *testing.T) {
 
 	logger.Info("test message")
 	assert.Contains(t, buf.String(), "test message")
+
+	// Writers need not be comparable.
+	buf.Reset()
+	log.SetOutput(multiWriter{&buf}, logger)
+	logger.Info("test message")
+	assert.Contains(t, buf.String(), "test message")
+}
+
+// multiWriter is a writer that is not comparable.
+type multiWriter []io.Writer
+
+func (m multiWriter) Write(p []byte) (int, error) {
+	for _, w := range m {
+		if _, err := w.Write(p); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
 }
 
 func TestSetPrefix(t *testing.T) {
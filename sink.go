@@ -0,0 +1,402 @@
+package log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrSinkClosed is returned when writing to a closed [Sink].
+var ErrSinkClosed = errors.New("log: sink closed")
+
+type (
+	// SinkOptions can be used to configure a [Sink].
+	SinkOptions struct {
+		BatchSize     int           // BatchSize is the maximum number of records per batch. Default is 100.
+		FlushInterval time.Duration // FlushInterval is the maximum time a record is held before sending. Default is 1s.
+		BufferSize    int           // BufferSize is the maximum number of buffered records. Default is 10000.
+		Block         bool          // Block is whether writes block when the buffer is full, instead of dropping the record. Default is false.
+		MaxRetries    int           // MaxRetries is the number of retries for a failed batch. Default is 5.
+		MinBackoff    time.Duration // MinBackoff is the delay before the first retry. Default is 100ms.
+		MaxBackoff    time.Duration // MaxBackoff is the maximum delay between retries. Default is 10s.
+		Timeout       time.Duration // Timeout is the timeout for sending a batch. Default is 10s.
+		Gzip          bool          // Gzip is whether HTTP request bodies are gzip compressed. Default is false.
+		Header        http.Header   // Header is added to each HTTP request. Default is no header.
+		Client        *http.Client  // Client is the HTTP client. Default is [http.DefaultClient].
+	}
+
+	// SinkOption is a sink option.
+	SinkOption func(*SinkOptions)
+
+	// SinkStats are the delivery counters of a [Sink].
+	SinkStats struct {
+		Sent     uint64 // Sent is the number of records delivered.
+		Failed   uint64 // Failed is the number of records discarded after all retries failed.
+		Dropped  uint64 // Dropped is the number of records discarded because the buffer was full.
+		Buffered uint64 // Buffered is the number of records waiting to be sent.
+	}
+)
+
+// Sink is an [io.Writer] that batches records and ships them to a network
+// endpoint. Each call to Write is treated as one record.
+//
+// Records are held in a bounded in-memory buffer while the endpoint is
+// unavailable. Failed batches are retried with exponential backoff. A
+// retry only resends the records not completely written by the failed
+// attempt, so a record cut off by a TCP error is sent again in full.
+type Sink struct {
+	o SinkOptions
+	// send sends the batch and returns the number of bytes delivered.
+	send func(ctx context.Context, batch []byte) (int, error)
+
+	mu     sync.Mutex
+	space  *sync.Cond // space is signaled when records are removed from the queue.
+	queue  [][]byte
+	closed bool
+
+	// The counters of the records added to and removed from the queue, and
+	// of the records discarded as of the last flush.
+	queued, processed, flushedLost uint64
+
+	conn net.Conn // conn is the TCP connection, owned by the run loop.
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+
+	sent, failed, dropped atomic.Uint64
+}
+
+var (
+	_ io.WriteCloser = (*Sink)(nil)
+	_ Flusher        = (*Sink)(nil)
+)
+
+// Flusher is implemented by writers that deliver records asynchronously,
+// such as [Sink]. A [DiskBuffer] only acknowledges the records forwarded to
+// a Flusher once Flush succeeds.
+type Flusher interface {
+	// Flush blocks until the records written before the call have been
+	// delivered or discarded. It returns an error if any record has been
+	// discarded since the previous call.
+	Flush() error
+}
+
+// NewSink returns a new sink shipping records to the given endpoint.
+//
+// The endpoint is either an HTTP(S) URL, to which batches are sent as
+// newline-delimited JSON POST requests, or a tcp://host:port address, to
+// which records are written as a stream. Sizes, intervals and the timeout
+// must be positive, and the retries and backoffs not negative.
+func NewSink(endpoint string, opts ...SinkOption) (*Sink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("log: invalid sink endpoint: %w", err)
+	}
+
+	o := SinkOptions{
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		BufferSize:    10000,
+		MaxRetries:    5,
+		MinBackoff:    100 * time.Millisecond,
+		MaxBackoff:    10 * time.Second,
+		Timeout:       10 * time.Second,
+		Client:        http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Sink{
+		o:      o,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.space = sync.NewCond(&s.mu)
+
+	switch u.Scheme {
+	case "http", "https":
+		s.send = func(ctx context.Context, batch []byte) (int, error) {
+			if err := s.sendHTTP(ctx, endpoint, batch); err != nil {
+				return 0, err
+			}
+			return len(batch), nil
+		}
+	case "tcp":
+		s.send = func(ctx context.Context, batch []byte) (int, error) {
+			return s.sendTCP(ctx, u.Host, batch)
+		}
+	default:
+		return nil, fmt.Errorf("log: unsupported sink endpoint scheme %q", u.Scheme)
+	}
+
+	go s.run()
+	return s, nil
+}
+
+// validate checks the options.
+func (o *SinkOptions) validate() error {
+	switch {
+	case o.BatchSize <= 0:
+		return fmt.Errorf("log: invalid sink batch size %d", o.BatchSize)
+	case o.FlushInterval <= 0:
+		return fmt.Errorf("log: invalid sink flush interval %s", o.FlushInterval)
+	case o.BufferSize <= 0:
+		return fmt.Errorf("log: invalid sink buffer size %d", o.BufferSize)
+	case o.MaxRetries < 0:
+		return fmt.Errorf("log: invalid sink max retries %d", o.MaxRetries)
+	case o.MinBackoff < 0 || o.MaxBackoff < 0:
+		return fmt.Errorf("log: invalid sink backoff %s-%s", o.MinBackoff, o.MaxBackoff)
+	case o.Timeout <= 0:
+		return fmt.Errorf("log: invalid sink timeout %s", o.Timeout)
+	case o.Client == nil:
+		return errors.New("log: invalid sink client: nil")
+	}
+	return nil
+}
+
+// Write buffers a copy of p as one record.
+func (s *Sink) Write(p []byte) (int, error) {
+	rec := bytes.Clone(p)
+	if len(rec) == 0 || rec[len(rec)-1] != '\n' {
+		rec = append(rec, '\n')
+	}
+
+	s.mu.Lock()
+	for s.o.Block && !s.closed && len(s.queue) >= s.o.BufferSize {
+		s.space.Wait()
+	}
+	if s.closed {
+		s.mu.Unlock()
+		return 0, ErrSinkClosed
+	}
+	if len(s.queue) >= s.o.BufferSize {
+		s.dropped.Add(1)
+		s.mu.Unlock()
+		return len(p), nil
+	}
+	s.queue = append(s.queue, rec)
+	s.queued++
+	full := len(s.queue) >= s.o.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Close stops accepting records and blocks until the buffered records
+// have been sent or have failed. Failed batches are retried without
+// waiting for the backoff.
+func (s *Sink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.space.Broadcast()
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+	return nil
+}
+
+// Flush blocks until the records written before the call have been sent
+// or have failed. It returns an error if records have failed or have been
+// dropped since the previous call.
+func (s *Sink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrSinkClosed
+	}
+	target := s.queued
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	for s.processed < target {
+		s.space.Wait()
+	}
+
+	lost := s.failed.Load() + s.dropped.Load()
+	n := lost - s.flushedLost
+	s.flushedLost = lost
+	if n > 0 {
+		return fmt.Errorf("log: sink discarded %d records", n)
+	}
+	return nil
+}
+
+// Stats returns the delivery counters.
+func (s *Sink) Stats() SinkStats {
+	s.mu.Lock()
+	buffered := len(s.queue)
+	s.mu.Unlock()
+	return SinkStats{
+		Sent:     s.sent.Load(),
+		Failed:   s.failed.Load(),
+		Dropped:  s.dropped.Load(),
+		Buffered: uint64(buffered),
+	}
+}
+
+// run sends batches until the sink is closed.
+func (s *Sink) run() {
+	defer close(s.done)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(s.o.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.notify:
+		case <-ticker.C:
+		case <-s.stop:
+			s.flush()
+			return
+		}
+		s.flush()
+	}
+}
+
+// flush sends the buffered records in batches.
+func (s *Sink) flush() {
+	for {
+		s.mu.Lock()
+		n := min(len(s.queue), s.o.BatchSize)
+		batch := s.queue[:n:n]
+		s.mu.Unlock()
+		if n == 0 {
+			return
+		}
+
+		err := s.sendWithRetry(batch)
+
+		s.mu.Lock()
+		clear(s.queue[:n])
+		s.queue = s.queue[n:]
+		s.processed += uint64(n)
+		if err != nil {
+			s.failed.Add(uint64(n))
+		} else {
+			s.sent.Add(uint64(n))
+		}
+		s.space.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+// sendWithRetry sends the records as one batch, retrying with exponential
+// backoff. A retry resumes after the records delivered completely by the
+// failed attempt, so that they are not sent twice.
+func (s *Sink) sendWithRetry(records [][]byte) error {
+	batch := bytes.Join(records, nil)
+	backoff := s.o.MinBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), s.o.Timeout)
+		n, err := s.send(ctx, batch)
+		cancel()
+		if err == nil || attempt >= s.o.MaxRetries {
+			return err
+		}
+		for len(records) > 0 && n >= len(records[0]) {
+			n -= len(records[0])
+			batch = batch[len(records[0]):]
+			records = records[1:]
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-s.stop:
+			timer.Stop()
+		}
+		backoff = min(2*backoff, s.o.MaxBackoff)
+	}
+}
+
+// sendHTTP posts the batch as newline-delimited JSON.
+func (s *Sink) sendHTTP(ctx context.Context, endpoint string, batch []byte) error {
+	body := batch
+	if s.o.Gzip {
+		var b bytes.Buffer
+		zw := gzip.NewWriter(&b)
+		if _, err := zw.Write(batch); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		body = b.Bytes()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range s.o.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if s.o.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.o.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("log: sink endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+// sendTCP writes the batch to the TCP connection, dialing if needed. It
+// returns the number of bytes written before an error.
+func (s *Sink) sendTCP(ctx context.Context, addr string, batch []byte) (int, error) {
+	if s.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return 0, err
+		}
+		s.conn = conn
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+	}
+	n, err := s.conn.Write(batch)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	return n, err
+}
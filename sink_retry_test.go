@@ -0,0 +1,29 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkRetryPartial(t *testing.T) {
+	var sent []string
+	s := &Sink{
+		o:    SinkOptions{MaxRetries: 2},
+		stop: make(chan struct{}),
+	}
+	s.send = func(_ context.Context, batch []byte) (int, error) {
+		sent = append(sent, string(batch))
+		if len(sent) == 1 {
+			// The first record and part of the second are written.
+			return len("one\ntw"), errors.New("connection reset")
+		}
+		return len(batch), nil
+	}
+
+	require.NoError(t, s.sendWithRetry([][]byte{[]byte("one\n"), []byte("two\n"), []byte("three\n")}))
+	assert.Equal(t, []string{"one\ntwo\nthree\n", "two\nthree\n"}, sent)
+}
@@ -0,0 +1,197 @@
+package log_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkHTTP(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		lines []string
+		fails atomic.Int32
+	)
+	fails.Store(2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fails.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		zr, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		mu.Lock()
+		lines = append(lines, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	sink, err := log.NewSink(srv.URL, func(o *log.SinkOptions) {
+		o.BatchSize = 2
+		o.FlushInterval = 10 * time.Millisecond
+		o.MinBackoff = time.Millisecond
+		o.Gzip = true
+		o.Header = http.Header{"Authorization": {"secret"}}
+	})
+	require.NoError(t, err)
+
+	logger := log.New(log.UseSink(sink))
+	logger.Info("one")
+	logger.Info("two")
+	logger.Info("three")
+	require.NoError(t, sink.Close())
+
+	require.Len(t, lines, 3)
+	for i, msg := range []string{"one", "two", "three"} {
+		var m map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &m))
+		assert.Equal(t, msg, m["msg"])
+	}
+	assert.Equal(t, log.SinkStats{Sent: 3}, sink.Stats())
+
+	_, err = sink.Write([]byte("four"))
+	assert.ErrorIs(t, err, log.ErrSinkClosed)
+}
+
+func TestSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	received := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			received <- sc.Text()
+		}
+	}()
+
+	sink, err := log.NewSink("tcp://"+ln.Addr().String(), func(o *log.SinkOptions) {
+		o.FlushInterval = 10 * time.Millisecond
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	logger := log.New(log.UseSink(sink))
+	logger.Info("one")
+	logger.Info("two")
+
+	for _, msg := range []string{"one", "two"} {
+		select {
+		case line := <-received:
+			assert.Contains(t, line, `"msg":"`+msg+`"`)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for record")
+		}
+	}
+}
+
+func TestSinkFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	sink, err := log.NewSink(srv.URL, func(o *log.SinkOptions) {
+		o.BufferSize = 2
+		o.FlushInterval = time.Hour
+		o.MaxRetries = 1
+		o.MinBackoff = time.Millisecond
+	})
+	require.NoError(t, err)
+
+	for range 3 {
+		_, err := sink.Write([]byte(`{"msg":"test message"}`))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, log.SinkStats{Dropped: 1, Buffered: 2}, sink.Stats())
+
+	require.NoError(t, sink.Close())
+	assert.Equal(t, log.SinkStats{Failed: 2, Dropped: 1}, sink.Stats())
+}
+
+func TestSinkCloseDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	sink, err := log.NewSink(srv.URL, func(o *log.SinkOptions) {
+		o.BatchSize = 1
+		o.MaxRetries = 3
+		o.MinBackoff = time.Hour
+		o.MaxBackoff = time.Hour
+	})
+	require.NoError(t, err)
+	_, err = sink.Write([]byte(`{"msg":"test message"}`))
+	require.NoError(t, err)
+
+	closed := make(chan struct{})
+	go func() {
+		assert.NoError(t, sink.Close())
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close waited for the backoff")
+	}
+	assert.Equal(t, log.SinkStats{Failed: 1}, sink.Stats())
+}
+
+func TestNewSink(t *testing.T) {
+	_, err := log.NewSink("udp://127.0.0.1:514")
+	assert.Error(t, err)
+
+	tests := []struct {
+		name string
+		opt  log.SinkOption
+	}{
+		{"ZeroBatchSize", func(o *log.SinkOptions) { o.BatchSize = 0 }},
+		{"NegativeBatchSize", func(o *log.SinkOptions) { o.BatchSize = -1 }},
+		{"ZeroFlushInterval", func(o *log.SinkOptions) { o.FlushInterval = 0 }},
+		{"NegativeFlushInterval", func(o *log.SinkOptions) { o.FlushInterval = -time.Second }},
+		{"ZeroBufferSize", func(o *log.SinkOptions) { o.BufferSize = 0 }},
+		{"ZeroBufferSizeBlock", func(o *log.SinkOptions) { o.BufferSize, o.Block = 0, true }},
+		{"NegativeMaxRetries", func(o *log.SinkOptions) { o.MaxRetries = -1 }},
+		{"NegativeBackoff", func(o *log.SinkOptions) { o.MinBackoff = -time.Second }},
+		{"ZeroTimeout", func(o *log.SinkOptions) { o.Timeout = 0 }},
+		{"NilClient", func(o *log.SinkOptions) { o.Client = nil }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sink, err := log.NewSink("http://127.0.0.1:1", tt.opt)
+			assert.Error(t, err)
+			assert.Nil(t, sink)
+		})
+	}
+
+	t.Run("ZeroMaxRetries", func(t *testing.T) {
+		sink, err := log.NewSink("http://127.0.0.1:1", func(o *log.SinkOptions) { o.MaxRetries = 0 })
+		require.NoError(t, err)
+		require.NoError(t, sink.Close())
+	})
+}
@@ -1,8 +1,12 @@
 package log
 
 import (
+	"context"
 	stdlog "log"
 	"log/slog"
+	"runtime"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
@@ -18,6 +22,26 @@ type (
 	StandardLogOption func(*StandardLogOptions)
 )
 
+// stdLogWriter is an [io.Writer] that logs each write as a record.
+type stdLogWriter struct {
+	l     *slog.Logger
+	level Level
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	ctx := context.Background()
+	if !w.l.Enabled(ctx, slog.Level(w.level)) {
+		return len(p), nil
+	}
+	var pcs [1]uintptr
+	// The caller stack is
+	// log.Printf() -> l.Output() -> w.Write()
+	runtime.Callers(4, pcs[:])
+	msg := strings.TrimSuffix(string(p), "\n")
+	r := slog.NewRecord(time.Now(), slog.Level(w.level), msg, pcs[0])
+	return len(p), w.l.Handler().Handle(ctx, r)
+}
+
 // StandardLog creates a new standard logger with the given options.
 func StandardLog(opts ...StandardLogOption) *stdlog.Logger {
 	o := &StandardLogOptions{}
@@ -25,13 +49,10 @@ func StandardLog(opts ...StandardLogOption) *stdlog.Logger {
 		opt(o)
 	}
 
-	if o.Logger != nil {
-		return o.Logger.Handler().(*log.Logger).StandardLog(o.StandardLogOptions)
-	}
-
-	if defaultOnce.l.Load() == nil {
-		_ = Default()
+	l := o.Logger
+	if l == nil {
+		l = Default()
 	}
 
-	return log.StandardLog(o.StandardLogOptions)
+	return stdlog.New(&stdLogWriter{l: l, level: o.ForceLevel}, "", 0)
 }
@@ -0,0 +1,129 @@
+package log
+
+import (
+	"maps"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CopyStyles returns a deep copy of the styles, which can be customized
+// without affecting s. A nil s returns [DefaultStyles].
+func CopyStyles(s *Styles) *Styles {
+	if s == nil {
+		return DefaultStyles()
+	}
+	c := *s
+	c.Levels = maps.Clone(s.Levels)
+	c.Keys = maps.Clone(s.Keys)
+	c.Values = maps.Clone(s.Values)
+	if c.Levels == nil {
+		c.Levels = map[Level]lipgloss.Style{}
+	}
+	if c.Keys == nil {
+		c.Keys = map[string]lipgloss.Style{}
+	}
+	if c.Values == nil {
+		c.Values = map[string]lipgloss.Style{}
+	}
+	return &c
+}
+
+// handlerStyles returns the styles of a handler: a copy of s, or the shared
+// default styles if s is nil, which saves copying them. Handlers never
+// modify their styles.
+func handlerStyles(s *Styles) *Styles {
+	if s == nil {
+		return defaultStyles()
+	}
+	return CopyStyles(s)
+}
+
+// StylesBuilder derives [Styles] from base styles. The base styles are
+// never modified, and each call to Build returns independent styles.
+//
+//	styles := log.NewStylesBuilder(nil).
+//		KeyStyle("err", lipgloss.NewStyle().Foreground(lipgloss.Color("204"))).
+//		Build()
+type StylesBuilder struct {
+	s *Styles
+}
+
+// NewStylesBuilder returns a builder deriving styles from base. A nil base
+// derives them from [DefaultStyles].
+func NewStylesBuilder(base *Styles) *StylesBuilder {
+	return &StylesBuilder{s: CopyStyles(base)}
+}
+
+// Timestamp sets the style of the timestamp.
+func (b *StylesBuilder) Timestamp(st lipgloss.Style) *StylesBuilder {
+	b.s.Timestamp = st
+	return b
+}
+
+// Caller sets the style of the caller.
+func (b *StylesBuilder) Caller(st lipgloss.Style) *StylesBuilder {
+	b.s.Caller = st
+	return b
+}
+
+// Prefix sets the style of the prefix.
+func (b *StylesBuilder) Prefix(st lipgloss.Style) *StylesBuilder {
+	b.s.Prefix = st
+	return b
+}
+
+// Message sets the style of the message.
+func (b *StylesBuilder) Message(st lipgloss.Style) *StylesBuilder {
+	b.s.Message = st
+	return b
+}
+
+// Key sets the default style of the attribute keys.
+func (b *StylesBuilder) Key(st lipgloss.Style) *StylesBuilder {
+	b.s.Key = st
+	return b
+}
+
+// Value sets the default style of the attribute values.
+func (b *StylesBuilder) Value(st lipgloss.Style) *StylesBuilder {
+	b.s.Value = st
+	return b
+}
+
+// Separator sets the style of the separator between keys and values.
+func (b *StylesBuilder) Separator(st lipgloss.Style) *StylesBuilder {
+	b.s.Separator = st
+	return b
+}
+
+// Level sets the style of the level. If the style has no label or maximum
+// width, those of the base style of the level are kept.
+func (b *StylesBuilder) Level(level Level, st lipgloss.Style) *StylesBuilder {
+	if base, ok := b.s.Levels[level]; ok {
+		if st.Value() == "" {
+			st = st.SetString(base.Value())
+		}
+		if st.GetMaxWidth() == 0 {
+			st = st.MaxWidth(base.GetMaxWidth())
+		}
+	}
+	b.s.Levels[level] = st
+	return b
+}
+
+// KeyStyle sets the style of the given attribute key.
+func (b *StylesBuilder) KeyStyle(key string, st lipgloss.Style) *StylesBuilder {
+	b.s.Keys[key] = st
+	return b
+}
+
+// ValueStyle sets the style of the values of the given attribute key.
+func (b *StylesBuilder) ValueStyle(key string, st lipgloss.Style) *StylesBuilder {
+	b.s.Values[key] = st
+	return b
+}
+
+// Build returns the styles.
+func (b *StylesBuilder) Build() *Styles {
+	return CopyStyles(b.s)
+}
@@ -0,0 +1,71 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultStylesIndependent(t *testing.T) {
+	s := log.DefaultStyles()
+	s.Levels[log.InfoLevel] = lipgloss.NewStyle().SetString("CHANGED")
+	s.Keys["err"] = lipgloss.NewStyle().Bold(true)
+
+	assert.Equal(t, "INFO", log.DefaultStyles().Levels[log.InfoLevel].Value())
+	assert.Empty(t, log.DefaultStyles().Keys)
+
+	var buf bytes.Buffer
+	log.New(log.UseOutput(&buf)).Info("message")
+	assert.Equal(t, "INFO message\n", buf.String())
+}
+
+func TestStylesCopiedByLogger(t *testing.T) {
+	var buf bytes.Buffer
+	s := log.DefaultStyles()
+	logger := log.New(log.UseOutput(&buf), log.UseStyles(s))
+	s.Levels[log.InfoLevel] = lipgloss.NewStyle().SetString("CHANGED")
+
+	logger.Info("message")
+	assert.Equal(t, "INFO message\n", buf.String())
+
+	buf.Reset()
+	log.SetStyles(s, logger)
+	logger.Info("message")
+	assert.Equal(t, "CHANGED message\n", buf.String())
+}
+
+func TestStylesBuilder(t *testing.T) {
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	base := log.DefaultStyles()
+	b := log.NewStylesBuilder(base).
+		Level(log.ErrorLevel, red).
+		KeyStyle("err", red).
+		ValueStyle("err", red.Bold(true)).
+		Timestamp(red)
+	styles := b.Build()
+
+	assert.Equal(t, "ERROR", styles.Levels[log.ErrorLevel].Value())
+	assert.Equal(t, 5, styles.Levels[log.ErrorLevel].GetMaxWidth())
+	assert.Equal(t, lipgloss.Color("9"), styles.Levels[log.ErrorLevel].GetForeground())
+	assert.Equal(t, lipgloss.Color("9"), styles.Keys["err"].GetForeground())
+	assert.True(t, styles.Values["err"].GetBold())
+	assert.Equal(t, lipgloss.Color("9"), styles.Timestamp.GetForeground())
+
+	// The base styles are not modified.
+	assert.Empty(t, base.Keys)
+	assert.NotEqual(t, lipgloss.Color("9"), base.Levels[log.ErrorLevel].GetForeground())
+
+	// Each build is independent.
+	other := b.Build()
+	other.Keys["err"] = lipgloss.NewStyle()
+	assert.Equal(t, lipgloss.Color("9"), b.Build().Keys["err"].GetForeground())
+
+	t.Run("Defaults", func(t *testing.T) {
+		styles := log.NewStylesBuilder(nil).KeyStyle("err", red).Build()
+		assert.Equal(t, "INFO", styles.Levels[log.InfoLevel].Value())
+		assert.Contains(t, styles.Keys, "err")
+	})
+}
@@ -0,0 +1,102 @@
+package log
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// terminalWidth returns the width of w if it is a terminal, falling back
+// to the COLUMNS environment variable, or 0 if w is not a terminal.
+func terminalWidth(w io.Writer) int {
+	if !isTerminal(w) {
+		return 0
+	}
+	if width, ok := terminalSize(w.(interface{ Fd() uintptr }).Fd()); ok {
+		return width
+	}
+	if width, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+// supportsHyperlinks reports whether w supports OSC 8 hyperlinks, that is
+// whether it is a terminal other than TERM=dumb. FORCE_HYPERLINK forces
+// hyperlinks on, or off with a value of 0.
+func supportsHyperlinks(w io.Writer) bool {
+	if v := os.Getenv("FORCE_HYPERLINK"); v != "" {
+		return v != "0"
+	}
+	return isTerminal(w) && os.Getenv("TERM") != "dumb"
+}
+
+// forcedColorProfile returns the color profile requested by FORCE_COLOR or
+// CLICOLOR_FORCE, if any. FORCE_COLOR may be a color level: 1 for 16
+// colors, 2 for 256 colors and 3 for true color.
+func forcedColorProfile() (termenv.Profile, bool) {
+	if v := os.Getenv("FORCE_COLOR"); v != "" {
+		switch strings.ToLower(v) {
+		case "0", "false":
+		case "1":
+			return termenv.ANSI, true
+		case "2":
+			return termenv.ANSI256, true
+		case "3":
+			return termenv.TrueColor, true
+		default:
+			return termenv.ANSI256, true
+		}
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return termenv.ANSI256, true
+	}
+	return termenv.Ascii, false
+}
+
+// detectOutput selects the formatter for w in the [AutoFormatter] mode, and
+// returns the renderer to use. The formatter is [TextFormatter] for a
+// terminal, or when colors are forced, and [JSONFormatter] otherwise.
+//
+// NO_COLOR takes precedence over FORCE_COLOR and CLICOLOR_FORCE, which take
+// precedence over TERM=dumb.
+func detectOutput(w io.Writer, re *lipgloss.Renderer) (Formatter, *lipgloss.Renderer) {
+	profile, forced := forcedColorProfile()
+	if !isTerminal(w) && !forced {
+		return JSONFormatter, re
+	}
+
+	switch {
+	case os.Getenv("NO_COLOR") != "":
+		profile = termenv.Ascii
+	case forced:
+		if p := termenv.NewOutput(w, termenv.WithUnsafe()).EnvColorProfile(); p < profile {
+			// Lower profiles have more colors.
+			profile = p
+		}
+	case os.Getenv("TERM") == "dumb":
+		profile = termenv.Ascii
+	default:
+		return TextFormatter, re
+	}
+	// The renderer is not shared, since its profile depends on the
+	// environment rather than on the writer alone.
+	re = lipgloss.NewRenderer(w, termenv.WithColorCache(true))
+	re.SetColorProfile(profile)
+	return TextFormatter, re
+}
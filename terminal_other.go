@@ -0,0 +1,9 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris || zos)
+
+package log
+
+// terminalSize returns the width of the terminal with the file descriptor.
+// It is not supported on this platform.
+func terminalSize(uintptr) (int, bool) {
+	return 0, false
+}
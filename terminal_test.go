@@ -0,0 +1,69 @@
+package log_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoFormatter(t *testing.T) {
+	for _, k := range []string{"NO_COLOR", "FORCE_COLOR", "CLICOLOR_FORCE"} {
+		t.Setenv(k, "")
+	}
+
+	t.Run("NotTerminal", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.AutoFormatter))
+		logger.Info("message")
+		assert.Equal(t, `{"level":"info","msg":"message"}`+"\n", buf.String())
+	})
+
+	t.Run("ForceColor", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.AutoFormatter))
+		logger.Info("message")
+		assert.Contains(t, buf.String(), "\x1b[")
+		assert.Contains(t, buf.String(), "INFO")
+	})
+
+	t.Run("CLIColorForce", func(t *testing.T) {
+		t.Setenv("CLICOLOR_FORCE", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.AutoFormatter))
+		logger.Info("message")
+		assert.Contains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("NoColor", func(t *testing.T) {
+		t.Setenv("FORCE_COLOR", "1")
+		t.Setenv("NO_COLOR", "1")
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.AutoFormatter))
+		logger.Info("message")
+		assert.Equal(t, "INFO message\n", buf.String())
+	})
+
+	t.Run("SetOutput", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.AutoFormatter))
+		t.Setenv("FORCE_COLOR", "0")
+		log.SetOutput(&buf, logger)
+		logger.Info("json")
+		t.Setenv("FORCE_COLOR", "1")
+		log.SetOutput(&buf, logger)
+		logger.Info("text")
+		assert.Contains(t, buf.String(), `{"level":"info","msg":"json"}`+"\n")
+		assert.Contains(t, buf.String(), "INFO")
+	})
+
+	t.Run("SetFormatter", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf))
+		log.SetFormatter(log.AutoFormatter, logger)
+		logger.Info("message")
+		assert.Equal(t, `{"level":"info","msg":"message"}`+"\n", buf.String())
+	})
+}
@@ -0,0 +1,325 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The text formatter is adapted from [github.com/charmbracelet/log].
+
+const (
+	separator       = "="
+	indentSeparator = "  │ "
+)
+
+// textFormatter writes the entry as styled text. It must be called with the
+// lock held.
+func (h *handler) textFormatter(b *bytes.Buffer, e entry) {
+	if h.columns != nil {
+		h.columnFormatter(b, e)
+		return
+	}
+
+	st := h.styles
+	first := true
+	write := func(s string) {
+		writeSpace(b, first)
+		b.WriteString(s)
+		first = false
+	}
+
+	if e.time.Key != "" {
+		write(st.Timestamp.Renderer(h.re).Render(h.clean(h.formatTime(e.time.Value))))
+	}
+	if e.lvl.Key != "" {
+		if lvl := h.formatLevel(e.lvl.Value); lvl != "" {
+			write(lvl)
+		}
+	}
+	if e.caller.Key != "" {
+		write(h.linkCaller(st.Caller.Renderer(h.re).Render("<"+h.clean(e.caller.Value.String())+">"), e))
+	}
+	if e.prefix.Key != "" {
+		write(st.Prefix.Renderer(h.re).Render(h.clean(e.prefix.Value.String()) + ":"))
+	}
+	if e.message.Key != "" {
+		write(st.Message.Renderer(h.re).Render(h.clean(e.message.Value.String())))
+	}
+
+	h.writeTextAttrs(b, e.attrs, write, &first, "")
+
+	// Add a newline to the end of the log message.
+	b.WriteByte('\n')
+}
+
+// writeTextAttrs writes the attributes with the write function, which
+// separates them from the previous fields, or as indented blocks for
+// multi-line values, with the margin before their indentation. It must be
+// called with the lock held.
+func (h *handler) writeTextAttrs(b *bytes.Buffer, attrs []slog.Attr, write func(string), first *bool, margin string) {
+	st := h.styles
+	sep := st.Separator.Renderer(h.re).Render(separator)
+	indentSep := margin + st.Separator.Renderer(h.re).Render(indentSeparator)
+	for i, a := range attrs {
+		if a.Key == "" {
+			continue
+		}
+		moreKeys := i < len(attrs)-1
+		key := h.clean(a.Key)
+		if h.pretty != nil && a.Value.Kind() == slog.KindAny {
+			if val, ok := h.prettyValue(a.Value.Any(), len(a.Key)); ok {
+				key = h.renderKey(key)
+				if !strings.Contains(val, "\n") {
+					write(key + sep + val)
+					continue
+				}
+				b.WriteString("\n" + margin + "  ")
+				b.WriteString(key)
+				b.WriteString(sep + "\n")
+				h.writeBlock(b, val, indentSep, moreKeys)
+				*first = false
+				continue
+			}
+		}
+		val := formatValue(a.Value)
+		raw := val == ""
+		if raw {
+			val = `""`
+		}
+		valueStyle := st.Value
+		if vs, ok := st.Values[a.Key]; ok {
+			valueStyle = vs
+		}
+		key = h.renderKey(key)
+
+		// Values may contain multiple lines, and that format
+		// is preserved, with each line prefixed with a "  | "
+		// to show it's part of a collection of lines, unless
+		// the output is sanitized, so that a value cannot
+		// forge records.
+		//
+		// Values may also need quoting, if not all the runes
+		// in the value string are "normal", like if they
+		// contain ANSI escape sequences.
+		switch {
+		case !h.sanitize && strings.Contains(val, "\n"):
+			b.WriteString("\n" + margin + "  ")
+			b.WriteString(key)
+			b.WriteString(sep + "\n")
+			h.writeIndent(b, val, indentSep, moreKeys, a.Key)
+			*first = false
+		case !raw && needsQuoting(val):
+			write(key + sep + valueStyle.Renderer(h.re).Render(
+				fmt.Sprintf(`"%s"`, escapeStringForOutput(val, true))))
+		default:
+			write(key + sep + valueStyle.Renderer(h.re).Render(val))
+		}
+	}
+
+}
+
+// formatTime returns the timestamp formatted with the time format, or the
+// elapsed time of the [ElapsedTime] and [DeltaTime] modes.
+func (h *handler) formatTime(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindTime:
+		return v.Time().Format(h.timeFormat)
+	case slog.KindDuration:
+		return formatElapsed(v.Duration())
+	}
+	return formatValue(v)
+}
+
+// formatLevel returns the styled level label.
+func (h *handler) formatLevel(v slog.Value) string {
+	if level, ok := v.Any().(Level); ok {
+		return h.levelText(level)
+	}
+	return formatValue(v)
+}
+
+// formatValue returns the string representation of the value.
+func formatValue(v slog.Value) string {
+	if v.Kind() == slog.KindAny {
+		return fmt.Sprintf("%+v", v.Any())
+	}
+	return v.String()
+}
+
+func (h *handler) writeIndent(w io.Writer, str string, indent string, newline bool, key string) {
+	st := h.styles
+
+	// kindly borrowed from hclog
+	for {
+		nl := strings.IndexByte(str, '\n')
+		if nl == -1 {
+			if str != "" {
+				_, _ = w.Write([]byte(indent))
+				val := escapeStringForOutput(str, false)
+				if valueStyle, ok := st.Values[key]; ok {
+					val = valueStyle.Renderer(h.re).Render(val)
+				} else {
+					val = st.Value.Renderer(h.re).Render(val)
+				}
+				_, _ = w.Write([]byte(val))
+				if newline {
+					_, _ = w.Write([]byte{'\n'})
+				}
+			}
+			return
+		}
+
+		_, _ = w.Write([]byte(indent))
+		val := escapeStringForOutput(str[:nl], false)
+		val = st.Value.Renderer(h.re).Render(val)
+		_, _ = w.Write([]byte(val))
+		_, _ = w.Write([]byte{'\n'})
+		str = str[nl+1:]
+	}
+}
+
+// renderKey returns the styled key.
+func (h *handler) renderKey(key string) string {
+	if keyStyle, ok := h.styles.Keys[key]; ok {
+		return keyStyle.Renderer(h.re).Render(key)
+	}
+	return h.styles.Key.Renderer(h.re).Render(key)
+}
+
+// writeBlock writes the lines of a pretty-printed value, each prefixed with
+// the indent.
+func (h *handler) writeBlock(b *bytes.Buffer, block string, indent string, newline bool) {
+	for i, line := range strings.Split(block, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(indent)
+		b.WriteString(line)
+	}
+	if newline {
+		b.WriteByte('\n')
+	}
+}
+
+func needsEscaping(str string) bool {
+	for _, b := range str {
+		if !unicode.IsPrint(b) || b == '"' {
+			return true
+		}
+	}
+
+	return false
+}
+
+const (
+	lowerhex = "0123456789abcdef"
+)
+
+var bufPool = sync.Pool{
+	New: func() interface{} {
+		return new(strings.Builder)
+	},
+}
+
+func escapeStringForOutput(str string, escapeQuotes bool) string {
+	// kindly borrowed from hclog
+	if !needsEscaping(str) {
+		return str
+	}
+
+	bb := bufPool.Get().(*strings.Builder)
+	bb.Reset()
+
+	defer bufPool.Put(bb)
+	for _, r := range str {
+		if escapeQuotes && r == '"' {
+			bb.WriteString(`\"`)
+		} else if unicode.IsPrint(r) {
+			bb.WriteRune(r)
+		} else {
+			switch r {
+			case '\a':
+				bb.WriteString(`\a`)
+			case '\b':
+				bb.WriteString(`\b`)
+			case '\f':
+				bb.WriteString(`\f`)
+			case '\n':
+				bb.WriteString(`\n`)
+			case '\r':
+				bb.WriteString(`\r`)
+			case '\t':
+				bb.WriteString(`\t`)
+			case '\v':
+				bb.WriteString(`\v`)
+			default:
+				switch {
+				case r < ' ':
+					bb.WriteString(`\x`)
+					bb.WriteByte(lowerhex[byte(r)>>4])
+					bb.WriteByte(lowerhex[byte(r)&0xF])
+				case !utf8.ValidRune(r):
+					r = 0xFFFD
+					fallthrough
+				case r < 0x10000:
+					bb.WriteString(`\u`)
+					for s := 12; s >= 0; s -= 4 {
+						bb.WriteByte(lowerhex[r>>uint(s)&0xF])
+					}
+				default:
+					bb.WriteString(`\U`)
+					for s := 28; s >= 0; s -= 4 {
+						bb.WriteByte(lowerhex[r>>uint(s)&0xF])
+					}
+				}
+			}
+		}
+	}
+
+	return bb.String()
+}
+
+func needsQuoting(s string) bool {
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if needsQuotingSet[b] {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+var needsQuotingSet = [utf8.RuneSelf]bool{
+	'"': true,
+	'=': true,
+}
+
+func init() {
+	for i := 0; i < utf8.RuneSelf; i++ {
+		r := rune(i)
+		if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			needsQuotingSet[i] = true
+		}
+	}
+}
+
+func writeSpace(w io.Writer, first bool) {
+	if !first {
+		w.Write([]byte{' '}) //nolint: errcheck
+	}
+}
@@ -0,0 +1,279 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names
+const (
+	ThemeDefault      = "default"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+	ThemeMonochrome   = "monochrome"
+	ThemeSolarized    = "solarized"
+
+	// ThemeAuto is resolved to [ThemeDark] or [ThemeLight] when the theme is
+	// applied, and again when the output is set, depending on the
+	// background color of the terminal.
+	ThemeAuto = "auto"
+)
+
+type (
+	// Theme describes the styles of the text formatter. It can be loaded
+	// from a JSON file, see [LoadTheme].
+	//
+	// Each style replaces the corresponding default style. Levels are keyed
+	// by level name, such as "info"; the levels that are not described keep
+	// their default style.
+	Theme struct {
+		Name      string                `json:"name"`
+		Timestamp ThemeStyle            `json:"timestamp"`
+		Caller    ThemeStyle            `json:"caller"`
+		Prefix    ThemeStyle            `json:"prefix"`
+		Message   ThemeStyle            `json:"message"`
+		Key       ThemeStyle            `json:"key"`
+		Value     ThemeStyle            `json:"value"`
+		Separator ThemeStyle            `json:"separator"`
+		Levels    map[string]ThemeStyle `json:"levels,omitempty"`
+		Keys      map[string]ThemeStyle `json:"keys,omitempty"`   // Keys are the styles of specific attribute keys.
+		Values    map[string]ThemeStyle `json:"values,omitempty"` // Values are the styles of the values of specific attribute keys.
+	}
+
+	// ThemeStyle describes a style. Colors are ANSI color numbers, such as
+	// "86", or hex colors, such as "#2aa198". An empty color is the terminal
+	// default.
+	ThemeStyle struct {
+		Foreground string `json:"foreground,omitempty"`
+		Background string `json:"background,omitempty"`
+		Bold       bool   `json:"bold,omitempty"`
+		Faint      bool   `json:"faint,omitempty"`
+		Italic     bool   `json:"italic,omitempty"`
+		Underline  bool   `json:"underline,omitempty"`
+		Reverse    bool   `json:"reverse,omitempty"`
+	}
+)
+
+// themes are the named presets.
+var themes = map[string]Theme{
+	ThemeDefault: {
+		Name:      ThemeDefault,
+		Caller:    ThemeStyle{Faint: true},
+		Prefix:    ThemeStyle{Bold: true, Faint: true},
+		Key:       ThemeStyle{Faint: true},
+		Separator: ThemeStyle{Faint: true},
+		Levels: map[string]ThemeStyle{
+			"debug": {Foreground: "63", Bold: true},
+			"info":  {Foreground: "86", Bold: true},
+			"warn":  {Foreground: "192", Bold: true},
+			"error": {Foreground: "204", Bold: true},
+			"fatal": {Foreground: "134", Bold: true},
+		},
+	},
+	ThemeDark: {
+		Name:      ThemeDark,
+		Timestamp: ThemeStyle{Foreground: "245"},
+		Caller:    ThemeStyle{Foreground: "243"},
+		Prefix:    ThemeStyle{Foreground: "252", Bold: true},
+		Message:   ThemeStyle{Foreground: "255"},
+		Key:       ThemeStyle{Foreground: "245"},
+		Value:     ThemeStyle{Foreground: "252"},
+		Separator: ThemeStyle{Foreground: "240"},
+		Levels: map[string]ThemeStyle{
+			"debug": {Foreground: "63", Bold: true},
+			"info":  {Foreground: "86", Bold: true},
+			"warn":  {Foreground: "192", Bold: true},
+			"error": {Foreground: "204", Bold: true},
+			"fatal": {Foreground: "134", Bold: true},
+		},
+	},
+	ThemeLight: {
+		Name:      ThemeLight,
+		Timestamp: ThemeStyle{Foreground: "240"},
+		Caller:    ThemeStyle{Foreground: "244"},
+		Prefix:    ThemeStyle{Foreground: "235", Bold: true},
+		Message:   ThemeStyle{Foreground: "232"},
+		Key:       ThemeStyle{Foreground: "242"},
+		Value:     ThemeStyle{Foreground: "235"},
+		Separator: ThemeStyle{Foreground: "246"},
+		Levels: map[string]ThemeStyle{
+			"debug": {Foreground: "27", Bold: true},
+			"info":  {Foreground: "29", Bold: true},
+			"warn":  {Foreground: "130", Bold: true},
+			"error": {Foreground: "160", Bold: true},
+			"fatal": {Foreground: "90", Bold: true},
+		},
+	},
+	ThemeHighContrast: {
+		Name:      ThemeHighContrast,
+		Timestamp: ThemeStyle{Foreground: "15"},
+		Caller:    ThemeStyle{Foreground: "15", Underline: true},
+		Prefix:    ThemeStyle{Foreground: "15", Bold: true},
+		Message:   ThemeStyle{Foreground: "15", Bold: true},
+		Key:       ThemeStyle{Foreground: "14"},
+		Value:     ThemeStyle{Foreground: "15"},
+		Separator: ThemeStyle{Foreground: "15"},
+		Levels: map[string]ThemeStyle{
+			"debug": {Foreground: "14", Bold: true},
+			"info":  {Foreground: "10", Bold: true},
+			"warn":  {Foreground: "11", Bold: true},
+			"error": {Foreground: "15", Background: "9", Bold: true},
+			"fatal": {Foreground: "15", Background: "13", Bold: true},
+		},
+	},
+	ThemeMonochrome: {
+		Name:      ThemeMonochrome,
+		Caller:    ThemeStyle{Faint: true},
+		Prefix:    ThemeStyle{Bold: true},
+		Key:       ThemeStyle{Faint: true},
+		Separator: ThemeStyle{Faint: true},
+		Levels: map[string]ThemeStyle{
+			"debug": {Faint: true},
+			"info":  {Bold: true},
+			"warn":  {Bold: true, Underline: true},
+			"error": {Bold: true, Reverse: true},
+			"fatal": {Bold: true, Reverse: true},
+		},
+	},
+	ThemeSolarized: {
+		Name:      ThemeSolarized,
+		Timestamp: ThemeStyle{Foreground: "#586e75"},
+		Caller:    ThemeStyle{Foreground: "#586e75"},
+		Prefix:    ThemeStyle{Foreground: "#268bd2", Bold: true},
+		Message:   ThemeStyle{Foreground: "#93a1a1"},
+		Key:       ThemeStyle{Foreground: "#586e75"},
+		Value:     ThemeStyle{Foreground: "#839496"},
+		Separator: ThemeStyle{Foreground: "#586e75"},
+		Levels: map[string]ThemeStyle{
+			"debug": {Foreground: "#6c71c4", Bold: true},
+			"info":  {Foreground: "#2aa198", Bold: true},
+			"warn":  {Foreground: "#b58900", Bold: true},
+			"error": {Foreground: "#dc322f", Bold: true},
+			"fatal": {Foreground: "#d33682", Bold: true},
+		},
+	},
+	ThemeAuto: {Name: ThemeAuto},
+}
+
+// LookupTheme returns the preset with the given name.
+func LookupTheme(name string) (Theme, bool) {
+	t, ok := themes[strings.ToLower(name)]
+	return t.clone(), ok
+}
+
+// ThemeNames returns the names of the presets.
+func ThemeNames() []string {
+	return []string{ThemeDefault, ThemeDark, ThemeLight, ThemeHighContrast, ThemeMonochrome, ThemeSolarized, ThemeAuto}
+}
+
+// ParseTheme parses a JSON theme.
+func ParseTheme(data []byte) (Theme, error) {
+	var t Theme
+	if err := json.Unmarshal(data, &t); err != nil {
+		return Theme{}, fmt.Errorf("log: invalid theme: %w", err)
+	}
+	for name := range t.Levels {
+		if _, err := ParseLevel(name); err != nil {
+			return Theme{}, fmt.Errorf("log: invalid theme: %w", err)
+		}
+	}
+	return t, nil
+}
+
+// LoadTheme loads a JSON theme from a file.
+func LoadTheme(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, fmt.Errorf("log: load theme: %w", err)
+	}
+	return ParseTheme(data)
+}
+
+// Styles returns new styles for the theme. The level labels and widths
+// are those of [DefaultStyles]. The [ThemeAuto] preset returns the styles
+// of [ThemeDark].
+func (t Theme) Styles() *Styles {
+	if t.Name == ThemeAuto {
+		t = themes[ThemeDark]
+	}
+	s := DefaultStyles()
+	s.Timestamp = t.Timestamp.style()
+	s.Caller = t.Caller.style()
+	s.Prefix = t.Prefix.style()
+	s.Message = t.Message.style()
+	s.Key = t.Key.style()
+	s.Value = t.Value.style()
+	s.Separator = t.Separator.style()
+	for name, ts := range t.Levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			continue
+		}
+		def, ok := s.Levels[level]
+		if !ok {
+			continue
+		}
+		s.Levels[level] = ts.style().
+			SetString(def.Value()).
+			MaxWidth(def.GetMaxWidth())
+	}
+	for k, ts := range t.Keys {
+		s.Keys[k] = ts.style()
+	}
+	for k, ts := range t.Values {
+		s.Values[k] = ts.style()
+	}
+	return s
+}
+
+// resolve returns the theme with [ThemeAuto] resolved for the renderer.
+func (t Theme) resolve(re *lipgloss.Renderer) Theme {
+	if t.Name != ThemeAuto {
+		return t
+	}
+	if re.HasDarkBackground() {
+		return themes[ThemeDark]
+	}
+	return themes[ThemeLight]
+}
+
+// clone returns a deep copy of the theme.
+func (t Theme) clone() Theme {
+	t.Levels = maps.Clone(t.Levels)
+	t.Keys = maps.Clone(t.Keys)
+	t.Values = maps.Clone(t.Values)
+	return t
+}
+
+// style returns the lipgloss style.
+func (ts ThemeStyle) style() lipgloss.Style {
+	s := lipgloss.NewStyle()
+	if ts.Foreground != "" {
+		s = s.Foreground(lipgloss.Color(ts.Foreground))
+	}
+	if ts.Background != "" {
+		s = s.Background(lipgloss.Color(ts.Background))
+	}
+	if ts.Bold {
+		s = s.Bold(true)
+	}
+	if ts.Faint {
+		s = s.Faint(true)
+	}
+	if ts.Italic {
+		s = s.Italic(true)
+	}
+	if ts.Underline {
+		s = s.Underline(true)
+	}
+	if ts.Reverse {
+		s = s.Reverse(true)
+	}
+	return s
+}
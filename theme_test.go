@@ -0,0 +1,96 @@
+package log_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bartventer/log"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThemes(t *testing.T) {
+	levels := []log.Level{log.DebugLevel, log.InfoLevel, log.WarnLevel, log.ErrorLevel, log.FatalLevel}
+
+	for _, name := range log.ThemeNames() {
+		t.Run(name, func(t *testing.T) {
+			theme, ok := log.LookupTheme(name)
+			require.True(t, ok)
+			styles := theme.Styles()
+			for _, level := range levels {
+				want := log.DefaultStyles().Levels[level]
+				got := styles.Levels[level]
+				assert.Equal(t, want.Value(), got.Value())
+				assert.Equal(t, want.GetMaxWidth(), got.GetMaxWidth())
+			}
+		})
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		theme, _ := log.LookupTheme(log.ThemeDefault)
+		got, want := theme.Styles(), log.DefaultStyles()
+		for _, level := range levels {
+			assert.Equal(t, want.Levels[level].GetForeground(), got.Levels[level].GetForeground())
+			assert.Equal(t, want.Levels[level].GetBold(), got.Levels[level].GetBold())
+		}
+		assert.Equal(t, want.Caller.GetFaint(), got.Caller.GetFaint())
+		assert.Equal(t, want.Prefix.GetBold(), got.Prefix.GetBold())
+		assert.Equal(t, want.Key.GetFaint(), got.Key.GetFaint())
+	})
+
+	t.Run("LookupReturnsCopy", func(t *testing.T) {
+		theme, _ := log.LookupTheme(log.ThemeSolarized)
+		theme.Levels["info"] = log.ThemeStyle{Foreground: "1"}
+		theme, _ = log.LookupTheme(log.ThemeSolarized)
+		assert.Equal(t, "#2aa198", theme.Levels["info"].Foreground)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, ok := log.LookupTheme("unknown")
+		assert.False(t, ok)
+	})
+}
+
+func TestLoadTheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.json")
+	data := `{
+		"name": "custom",
+		"timestamp": {"foreground": "240"},
+		"key": {"foreground": "#00ff00", "italic": true},
+		"levels": {"info": {"foreground": "#ff0000", "bold": true}},
+		"keys": {"err": {"foreground": "9"}}
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+
+	theme, err := log.LoadTheme(path)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", theme.Name)
+
+	styles := theme.Styles()
+	assert.Equal(t, lipgloss.Color("240"), styles.Timestamp.GetForeground())
+	assert.Equal(t, lipgloss.Color("#00ff00"), styles.Key.GetForeground())
+	assert.True(t, styles.Key.GetItalic())
+	assert.Equal(t, lipgloss.Color("#ff0000"), styles.Levels[log.InfoLevel].GetForeground())
+	assert.Equal(t, "INFO", styles.Levels[log.InfoLevel].Value())
+	assert.Equal(t, lipgloss.Color("9"), styles.Keys["err"].GetForeground())
+	// Levels that are not described keep their default style.
+	assert.Equal(t, log.DefaultStyles().Levels[log.WarnLevel].GetForeground(), styles.Levels[log.WarnLevel].GetForeground())
+
+	_, err = log.ParseTheme([]byte(`{"levels": {"verbose": {}}}`))
+	assert.Error(t, err)
+	_, err = log.LoadTheme(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestUseTheme(t *testing.T) {
+	for _, name := range []string{log.ThemeMonochrome, log.ThemeAuto} {
+		theme, _ := log.LookupTheme(name)
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseTheme(theme))
+		logger.Info("message", "key", "value")
+		assert.Equal(t, "INFO message key=value\n", buf.String())
+	}
+}
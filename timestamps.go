@@ -0,0 +1,79 @@
+package log
+
+import (
+	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// TimeMode is the mode of the timestamps.
+type TimeMode uint8
+
+// Time modes
+const (
+	// AbsoluteTime is the time of the record, formatted with the time
+	// format.
+	AbsoluteTime TimeMode = iota
+
+	// ElapsedTime is the time elapsed since the logger was created, such
+	// as "+1.234s". It is computed from the record times, without the time
+	// function.
+	ElapsedTime
+
+	// DeltaTime is the time elapsed since the previous record of the
+	// logger, or since the logger was created for the first record. It is
+	// computed from the record times, without the time function. A record
+	// older than the previous one, as can happen with concurrent loggers,
+	// has a delta of zero.
+	DeltaTime
+)
+
+// clock tracks the creation time of a logger and the time of its previous
+// record. It is shared by the loggers derived from the logger.
+type clock struct {
+	start time.Time
+	prev  atomic.Int64 // prev is the time of the previous record in Unix nanoseconds.
+}
+
+// newClock returns a clock started now.
+func newClock() *clock {
+	c := &clock{start: time.Now()}
+	c.prev.Store(c.start.UnixNano())
+	return c
+}
+
+// advance sets the time of the previous record to t, and returns the time
+// elapsed since it, or zero if t is before it.
+func (c *clock) advance(t time.Time) time.Duration {
+	now := t.UnixNano()
+	return time.Duration(max(now-c.prev.Swap(now), 0))
+}
+
+// timestamp returns the timestamp field of a record logged at t. It must
+// be called with the lock held.
+func (h *handler) timestamp(t time.Time) slog.Attr {
+	delta := h.clock.advance(t)
+	switch h.timeMode {
+	case ElapsedTime:
+		return slog.Duration(h.keys.Time, t.Sub(h.clock.start))
+	case DeltaTime:
+		return slog.Duration(h.keys.Time, delta)
+	}
+	t = h.timeFunc(t)
+	if h.timeLocation != nil {
+		t = t.In(h.timeLocation)
+	}
+	return slog.Time(h.keys.Time, t)
+}
+
+// formatElapsed formats the elapsed time as signed seconds with millisecond
+// precision, such as "+1.234s".
+func formatElapsed(d time.Duration) string {
+	b := make([]byte, 0, 16)
+	if d >= 0 {
+		b = append(b, '+')
+	}
+	b = strconv.AppendFloat(b, d.Seconds(), 'f', 3, 64)
+	return string(append(b, 's'))
+}
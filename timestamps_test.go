@@ -0,0 +1,101 @@
+package log_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/bartventer/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeMode(t *testing.T) {
+	handle := func(t *testing.T, logger *slog.Logger, at time.Time) {
+		t.Helper()
+		r := slog.NewRecord(at, slog.LevelInfo, "message", 0)
+		require.NoError(t, logger.Handler().Handle(context.Background(), r))
+	}
+
+	t.Run("Elapsed", func(t *testing.T) {
+		var buf bytes.Buffer
+		start := time.Now()
+		logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true), log.UseTimeMode(log.ElapsedTime))
+		handle(t, logger, start.Add(-time.Second))
+		assert.Regexp(t, `^-1\.\d{3}s INFO message\n$`, buf.String())
+		buf.Reset()
+		handle(t, logger, time.Now().Add(2*time.Second))
+		assert.Regexp(t, `^\+2\.\d{3}s INFO message\n$`, buf.String())
+	})
+
+	t.Run("Delta", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true), log.UseTimeMode(log.DeltaTime))
+		at := time.Now()
+		handle(t, logger, at)
+		handle(t, logger.With("key", "value"), at.Add(1500*time.Millisecond))
+		handle(t, logger, at.Add(1750*time.Millisecond))
+		lines := bytes.SplitAfter(buf.Bytes(), []byte("\n"))
+		assert.Regexp(t, `^\+0\.\d{3}s INFO message\n$`, string(lines[0]))
+		assert.Equal(t, "+1.500s INFO message key=value\n", string(lines[1]))
+		assert.Equal(t, "+0.250s INFO message\n", string(lines[2]))
+	})
+
+	t.Run("DeltaOutOfOrder", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true), log.UseTimeMode(log.DeltaTime))
+		at := time.Now()
+		handle(t, logger, at.Add(time.Second))
+		buf.Reset()
+		handle(t, logger.With("key", "value"), at)
+		handle(t, logger, at.Add(250*time.Millisecond))
+		assert.Equal(t, "+0.000s INFO message key=value\n+0.250s INFO message\n", buf.String())
+	})
+
+	t.Run("TimeFunction", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true), log.UseTimeMode(log.DeltaTime),
+			log.UseTimeFunction(func(time.Time) time.Time { return time.Time{} }))
+		at := time.Now()
+		handle(t, logger, at)
+		buf.Reset()
+		handle(t, logger, at.Add(time.Second))
+		assert.Equal(t, "+1.000s INFO message\n", buf.String())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseFormatter(log.JSONFormatter),
+			log.UseReportTimestamp(true), log.UseTimeMode(log.DeltaTime))
+		at := time.Now()
+		handle(t, logger, at)
+		buf.Reset()
+		handle(t, logger, at.Add(42*time.Millisecond))
+		assert.Equal(t, `{"level":"info","msg":"message","time":"+0.042s"}`+"\n", buf.String())
+	})
+
+	t.Run("SetTimeMode", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true), log.UseTimeFormat(time.Kitchen))
+		at := time.Date(2024, 1, 2, 15, 4, 0, 0, time.Local)
+		handle(t, logger, at)
+		log.SetTimeMode(log.DeltaTime, logger)
+		handle(t, logger, at.Add(time.Second))
+		assert.Equal(t, "3:04PM INFO message\n+1.000s INFO message\n", buf.String())
+	})
+}
+
+func TestTimeLocation(t *testing.T) {
+	at := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	var buf bytes.Buffer
+	logger := log.New(log.UseOutput(&buf), log.UseReportTimestamp(true),
+		log.UseTimeFormat(time.RFC3339), log.UseTimeLocation(time.FixedZone("CET", 3600)))
+	r := slog.NewRecord(at, slog.LevelInfo, "message", 0)
+	require.NoError(t, logger.Handler().Handle(context.Background(), r))
+
+	log.SetTimeLocation(time.UTC, logger)
+	require.NoError(t, logger.Handler().Handle(context.Background(), r))
+	assert.Equal(t, "2024-01-02T04:04:05+01:00 INFO message\n2024-01-02T03:04:05Z INFO message\n", buf.String())
+}